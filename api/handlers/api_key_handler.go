@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/errorlog"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+type APIKeyHandler struct {
+	Manager              *business.Manager
+	ErrorResponseHandler *errorlog.HTTPErrorResponseWriterAndLogger
+}
+
+func NewAPIKeyHandler(m *business.Manager, errorLogger *log.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		Manager:              m,
+		ErrorResponseHandler: &errorlog.HTTPErrorResponseWriterAndLogger{Logger: errorLogger},
+	}
+}
+
+// HandlePostAPIKey issues a key; the response is the only time it is shown.
+func (h *APIKeyHandler) HandlePostAPIKey(ctx *gin.Context) {
+	var params types.NewAPIKeyParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	key, err := h.Manager.CreateAPIKey(ctx, ctx.GetString("userID"), params)
+	if err != nil {
+		var httpError errorlog.AppError
+		switch {
+		case errors.Is(err, types.ErrScopeNotGranted):
+			httpError = errorlog.BadRequestError(err)
+			httpError.Details = err.Error()
+		case errors.Is(err, types.ErrNotFound):
+			httpError = errorlog.NotFoundError(err)
+		default:
+			httpError = errorlog.InternalServerError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// HandleGetAPIKeys lists all keys, or those of the user_id query parameter.
+func (h *APIKeyHandler) HandleGetAPIKeys(ctx *gin.Context) {
+	keys, err := h.Manager.ListAPIKeys(ctx, ctx.Query("user_id"))
+	if err != nil {
+		httpError := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) HandleRevokeAPIKey(ctx *gin.Context) {
+	if err := h.Manager.RevokeAPIKey(ctx, ctx.Param("id")); err != nil {
+		httpError := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrNotFound) {
+			httpError = errorlog.NotFoundError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -1,12 +1,21 @@
 package handlers
 
 import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/auth"
 	"github.com/mkabdelrahman/hotel-reservation/business"
 	"github.com/mkabdelrahman/hotel-reservation/errorlog"
+	"github.com/mkabdelrahman/hotel-reservation/oidc"
+	"github.com/mkabdelrahman/hotel-reservation/types"
 )
 
 type AuthHandler struct {
@@ -30,13 +39,355 @@ func (h *AuthHandler) HandleAuthenticate(c *gin.Context) {
 		return
 	}
 
-	token, err := h.Manager.GetUserToken(c, authParams)
+	authParams.ClientIP = c.ClientIP()
 
+	tokens, challenge, err := h.Manager.GetUserToken(c, authParams)
+
+	if err != nil {
+		h.handleLoginError(c, err)
+		return
+	}
+
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// HandleCompleteMFALogin trades the challenge from HandleAuthenticate and a
+// TOTP or recovery code for tokens.
+func (h *AuthHandler) HandleCompleteMFALogin(c *gin.Context) {
+	var params types.MFALoginParams
+
+	if err := c.BindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	tokens, err := h.Manager.CompleteMFALogin(c, params, c.ClientIP())
+	if err != nil {
+		h.handleLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// HandleEnrollMFA sets up TOTP for a user logging in whose role requires it.
+func (h *AuthHandler) HandleEnrollMFA(c *gin.Context) {
+	var params types.MFAEnrollParams
+
+	if err := c.BindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	enrollment, err := h.Manager.EnrollTOTPWithChallenge(c, params)
+	if err != nil {
+		h.handleLoginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// handleLoginError answers a failed step of logging in.
+func (h *AuthHandler) handleLoginError(c *gin.Context, err error) {
+	var appErr errorlog.AppError
+	var throttled *types.LoginThrottledError
+	switch {
+	case errors.Is(err, types.ErrInvalidCredentials), errors.Is(err, types.ErrInvalidMFACode):
+		appErr = errorlog.UnauthorizedError(err)
+		appErr.Details = err.Error()
+	case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenExpired), errors.Is(err, types.ErrNotFound):
+		// The challenge is bad or stale, or its user is gone: start over
+		appErr = errorlog.UnauthorizedError(err)
+		appErr.Details = "mfa_token is invalid or expired, please log in again"
+	case errors.As(err, &throttled):
+		// Round up so clients never retry a moment too early
+		retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		appErr = errorlog.TooManyRequestsError(err)
+		appErr.Details = gin.H{"retryAfter": retryAfter}
+	case isAccountDisabled(err):
+		appErr = errorlog.ForbiddenError(err)
+		appErr.Details = err.Error()
+	case errors.Is(err, types.ErrMFANotEnrolled):
+		appErr = errorlog.BadRequestError(err)
+		appErr.Details = err.Error()
+	case errors.Is(err, types.ErrMFAAlreadyEnabled):
+		appErr = errorlog.ConflictError(err)
+		appErr.Details = err.Error()
+	default:
+		appErr = errorlog.InternalServerError(err)
+	}
+	h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+}
+
+func (h *AuthHandler) HandleRefresh(c *gin.Context) {
+	var params types.RefreshParams
+
+	if err := c.BindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	tokens, err := h.Manager.RefreshSession(c, params.RefreshToken)
+	if err != nil {
+		var appErr errorlog.AppError
+		switch {
+		case errors.Is(err, types.ErrSessionRevoked):
+			appErr = errorlog.UnauthorizedError(err)
+		case errors.Is(err, types.ErrMFARequired):
+			appErr = errorlog.UnauthorizedError(err)
+			appErr.Details = err.Error()
+		case isAccountDisabled(err):
+			appErr = errorlog.ForbiddenError(err)
+			appErr.Details = err.Error()
+		default:
+			appErr = errorlog.InternalServerError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// HandleLogout revokes the session of the access token it is called with.
+func (h *AuthHandler) HandleLogout(c *gin.Context) {
+	if err := h.Manager.Logout(c, c.GetString("sessionID")); err != nil {
+		appErr := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// HandleJWKS publishes the public keys of RS256 and EdDSA signing keys.
+func (h *AuthHandler) HandleJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Manager.Tokens.JWKS())
+}
+
+// HandleRequestPasswordReset answers the same whether or not the email is
+// registered.
+func (h *AuthHandler) HandleRequestPasswordReset(c *gin.Context) {
+	var params types.PasswordResetRequestParams
+
+	if err := c.BindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := h.Manager.RequestPasswordReset(c, params); err != nil {
+		appErr := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email is registered, a reset code has been sent to it"})
+}
+
+func (h *AuthHandler) HandleResetPassword(c *gin.Context) {
+	var params types.PasswordResetParams
+
+	if err := c.BindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := h.Manager.ResetPassword(c, params); err != nil {
+		var appErr errorlog.AppError
+		if errors.Is(err, types.ErrInvalidUserToken) {
+			appErr = errorlog.BadRequestError(err)
+		} else {
+			appErr = errorlog.InternalServerError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset, please log in"})
+}
+
+// isAccountDisabled reports whether err refuses an account for its status.
+func isAccountDisabled(err error) bool {
+	return errors.Is(err, types.ErrAccountSuspended) || errors.Is(err, types.ErrAccountDeleted) ||
+		errors.Is(err, types.ErrAccountNotVerified)
+}
+
+func (h *AuthHandler) HandleVerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		appErr := errorlog.BadRequestError(errors.New("token is required"))
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if _, err := h.Manager.VerifyEmail(c, token); err != nil {
+		var appErr errorlog.AppError
+		switch {
+		case errors.Is(err, types.ErrInvalidUserToken):
+			appErr = errorlog.BadRequestError(err)
+		case errors.Is(err, types.ErrNotFound):
+			appErr = errorlog.NotFoundError(err)
+		case isAccountDisabled(err):
+			appErr = errorlog.ForbiddenError(err)
+			appErr.Details = err.Error()
+		default:
+			appErr = errorlog.InternalServerError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email has been verified, you can log in now"})
+}
+
+// HandleResendVerification answers the same whether or not the email is
+// registered.
+func (h *AuthHandler) HandleResendVerification(c *gin.Context) {
+	var params types.ResendVerificationParams
+
+	if err := c.BindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	if err := h.Manager.ResendVerification(c, params); err != nil {
+		appErr := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email awaits verification, a new link has been sent to it"})
+}
+
+// oidcStateCookie holds the state of the OIDC login the browser started, so
+// the callback only completes logins started by the same browser.
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/auth/oidc"
+)
+
+// HandleStartOIDCLogin sends the user to the identity provider to log in.
+func (h *AuthHandler) HandleStartOIDCLogin(c *gin.Context) {
+	authURL, state, err := h.Manager.StartOIDCLogin(c, c.Param("provider"))
 	if err != nil {
-		appErr := errorlog.UnauthorizedError(err)
+		h.handleOIDCError(c, err)
+		return
+	}
+
+	h.setOIDCStateCookie(c, state, int(business.OIDCLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// HandleOIDCCallback is where the identity provider sends the user back. It
+// answers like HandleAuthenticate.
+func (h *AuthHandler) HandleOIDCCallback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		appErr := errorlog.UnauthorizedError(fmt.Errorf("identity provider: %s", providerErr))
+		appErr.Details = gin.H{"error": providerErr, "error_description": c.Query("error_description")}
+		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
+		return
+	}
+
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		appErr := errorlog.BadRequestError(errors.New("state and code are required"))
 		h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	cookieState, err := c.Cookie(oidcStateCookie)
+	h.setOIDCStateCookie(c, "", -1)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) != 1 {
+		h.handleOIDCError(c, types.ErrInvalidOIDCState)
+		return
+	}
+
+	tokens, challenge, err := h.Manager.CompleteOIDCLogin(c, c.Param("provider"), state, code)
+	if err != nil {
+		h.handleOIDCError(c, err)
+		return
+	}
+
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// setOIDCStateCookie sets the state cookie, or deletes it when maxAge is
+// negative. It is Lax so the provider's redirect back carries it.
+func (h *AuthHandler) setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := strings.HasPrefix(h.Manager.PublicURL, "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcStateCookiePath, "", secure, true)
+}
+
+func (h *AuthHandler) handleOIDCError(c *gin.Context, err error) {
+	var appErr errorlog.AppError
+	switch {
+	case errors.Is(err, types.ErrUnknownProvider):
+		appErr = errorlog.NotFoundError(err)
+	case errors.Is(err, types.ErrInvalidOIDCState):
+		appErr = errorlog.BadRequestError(err)
+		appErr.Details = "the login is invalid or expired, please start again"
+	case errors.Is(err, oidc.ErrExchangeFailed), errors.Is(err, oidc.ErrInvalidIDToken):
+		appErr = errorlog.UnauthorizedError(err)
+	case errors.Is(err, types.ErrOIDCEmailNotVerified), isAccountDisabled(err):
+		appErr = errorlog.ForbiddenError(err)
+		appErr.Details = err.Error()
+	default:
+		appErr = errorlog.InternalServerError(err)
+	}
+	h.ErrorResponseHandler.LogAndHandleError(c.Writer, c.Writer, appErr)
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"log"
 	"net/http"
@@ -52,7 +53,7 @@ func (h *BookingHandler) HandlePostBooking(ctx *gin.Context) {
 	insertedBooking, err := h.Manager.AddNewBooking(ctx, params)
 
 	if err != nil {
-		appErr := errorlog.InternalServerError(err)
+		appErr := bookingError(err)
 		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
 		return
 	}
@@ -66,7 +67,7 @@ func (h *BookingHandler) HandleGetBooking(ctx *gin.Context) {
 	booking, err := h.Manager.BookingStore.GetBookingByID(ctx, id)
 
 	if err != nil {
-		appErr := errorlog.InternalServerError(err)
+		appErr := bookingError(err)
 		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
 		return
 	}
@@ -89,13 +90,97 @@ func (h *BookingHandler) HandleGetBookings(ctx *gin.Context) {
 func (h *BookingHandler) HandleCancelBooking(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	err := h.Manager.CancelBooking(ctx, id)
+	booking, err := h.Manager.CancelBooking(ctx, id)
 
 	if err != nil {
-		appErr := errorlog.InternalServerError(err)
+		appErr := bookingError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "Booking canceled successfully",
+		"refund_amount":  booking.Cancellation.RefundAmount,
+		"penalty_amount": booking.Cancellation.PenaltyAmount,
+		"currency":       booking.Currency,
+	})
+}
+
+func (h *BookingHandler) HandleModifyBooking(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var params types.ModifyBookingParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	booking, err := h.Manager.ModifyBooking(ctx, id, params)
+	if err != nil {
+		appErr := bookingError(err)
 		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Booking canceled successfully"})
+	ctx.JSON(http.StatusOK, booking)
+}
+
+func (h *BookingHandler) HandleConfirmBooking(ctx *gin.Context) {
+	h.handleTransition(ctx, h.Manager.ConfirmBooking)
+}
+
+func (h *BookingHandler) HandleCheckInBooking(ctx *gin.Context) {
+	h.handleTransition(ctx, h.Manager.CheckInBooking)
+}
+
+func (h *BookingHandler) HandleCheckOutBooking(ctx *gin.Context) {
+	h.handleTransition(ctx, h.Manager.CheckOutBooking)
+}
+
+func (h *BookingHandler) HandleNoShowBooking(ctx *gin.Context) {
+	h.handleTransition(ctx, h.Manager.MarkBookingNoShow)
+}
+
+func (h *BookingHandler) handleTransition(ctx *gin.Context, transition func(context.Context, string) (*types.Booking, error)) {
+	id := ctx.Param("id")
+
+	booking, err := transition(ctx, id)
+	if err != nil {
+		appErr := bookingError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
+// bookingError maps errors of the booking use cases to HTTP errors.
+func bookingError(err error) errorlog.AppError {
+	var conflictErr *types.BookingConflictError
+
+	switch {
+	case errors.As(err, &conflictErr):
+		appErr := errorlog.ConflictError(err)
+		appErr.Details = gin.H{"room_id": conflictErr.RoomID, "conflicting_nights": conflictErr.Nights}
+		return appErr
+	case errors.Is(err, business.ErrBookingInReservation):
+		appErr := errorlog.ConflictError(err)
+		appErr.Details = "the booking is a line of a reservation; cancel the line and book again instead"
+		return appErr
+	case errors.Is(err, types.ErrRoomUnavailable), errors.Is(err, business.ErrInvalidTransition), errors.Is(err, business.ErrBookingNotModifiable):
+		return errorlog.ConflictError(err)
+	case errors.Is(err, types.ErrInvalidStay), errors.Is(err, types.ErrOverCapacity):
+		return errorlog.BadRequestError(err)
+	case errors.Is(err, types.ErrNotFound):
+		return errorlog.NotFoundError(err)
+	default:
+		return errorlog.InternalServerError(err)
+	}
 }
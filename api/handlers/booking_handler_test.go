@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/db/memory"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestHandleCheckOutPendingBooking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	manager := business.NewManager(memory.NewStores())
+	userID, err := manager.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotelID, err := manager.AddNewHotel(ctx, types.NewHotelParams{Name: "Dolcica", Location: "Madrid", Rating: types.Excellent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomID, err := manager.AddNewRoom(ctx, types.NewRoomParams{Number: "101", Type: types.StandardRoom, Price: 100}, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	bookingID, err := manager.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomID, FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewBookingHandler(manager, log.New(io.Discard, "", 0))
+	engine := gin.New()
+	engine.POST("/booking/:id/check-out", handler.HandleCheckOutBooking)
+
+	// A guest who never checked in cannot check out
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/booking/"+bookingID+"/check-out", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusConflict)
+	}
+}
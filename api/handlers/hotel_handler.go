@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
@@ -37,10 +38,10 @@ func (h *HotelHandler) HandleGetHotels(ctx *gin.Context) {
 func (h *HotelHandler) HandleGetHotel(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	hotel, err := h.Manager.HotelStore.GetHotel(ctx, id)
+	hotel, err := h.Manager.GetHotel(ctx, id)
 
 	if err != nil {
-		appErr := errorlog.InternalServerError(err)
+		appErr := catalogError(err)
 		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
 		return
 	}
@@ -57,6 +58,12 @@ func (h *HotelHandler) HandleHotelSearch(ctx *gin.Context) {
 		return
 	}
 
+	if err := q.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
 	hotels, err := h.Manager.QueryHotels(ctx, q)
 	if err != nil {
 		appErr := errorlog.InternalServerError(err)
@@ -70,25 +77,169 @@ func (h *HotelHandler) HandleHotelSearch(ctx *gin.Context) {
 func (h *HotelHandler) HandleGetHotelRooms(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	hotel, err := h.Manager.HotelStore.GetHotel(ctx, id)
+	rooms, err := h.Manager.ListRoomsForHotel(ctx, id)
 
 	if err != nil {
-		appErr := errorlog.InternalServerError(err)
+		appErr := catalogError(err)
 		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
 		return
 	}
 
-	var rooms []*types.Room
+	ctx.JSON(http.StatusOK, rooms)
+}
+
+func (h *HotelHandler) HandleGetHotelAvailability(ctx *gin.Context) {
+	id := ctx.Param("id")
 
-	for _, roomID := range hotel.Rooms {
-		room, err := h.Manager.RoomStore.GetRoomByID(ctx, roomID)
-		if err != nil {
-			appErr := errorlog.InternalServerError(err)
-			h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
-			return
+	var q types.AvailabilityQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := q.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	availability, err := h.Manager.CheckAvailability(ctx, id, q.From, q.Till, q.Guests)
+	if err != nil {
+		appErr := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrNotFound) {
+			appErr = errorlog.NotFoundError(err)
 		}
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
 
-		rooms = append(rooms, room)
+func (h *HotelHandler) HandlePostHotel(ctx *gin.Context) {
+	var params types.NewHotelParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	hotelID, err := h.Manager.AddNewHotel(ctx, params)
+	if err != nil {
+		appErr := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": hotelID})
+}
+
+func (h *HotelHandler) HandlePutHotel(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var params types.NewHotelParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	hotel, err := h.Manager.ReplaceHotel(ctx, id, params)
+	if err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hotel)
+}
+
+func (h *HotelHandler) HandlePatchHotel(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var params types.UpdateHotelParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	hotel, err := h.Manager.UpdateHotel(ctx, id, params)
+	if err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hotel)
+}
+
+func (h *HotelHandler) HandleDeleteHotel(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := h.Manager.DeleteHotel(ctx, id); err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "hotel has been deleted"})
+}
+
+func (h *HotelHandler) HandlePostHotelRoom(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var params types.NewRoomParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	roomID, err := h.Manager.AddNewRoom(ctx, params, id)
+	if err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": roomID})
+}
+
+// catalogError maps errors of the hotel and room admin use cases to responses.
+func catalogError(err error) errorlog.AppError {
+	switch {
+	case errors.Is(err, business.ErrHasFutureBookings):
+		return errorlog.ConflictError(err)
+	case errors.Is(err, types.ErrNotFound):
+		return errorlog.NotFoundError(err)
+	default:
+		return errorlog.InternalServerError(err)
 	}
-	ctx.JSON(http.StatusOK, rooms)
 }
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/errorlog"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+type ReservationHandler struct {
+	Manager              *business.Manager
+	ErrorResponseHandler *errorlog.HTTPErrorResponseWriterAndLogger
+}
+
+func NewReservationHandler(m *business.Manager, errorLogger *log.Logger) *ReservationHandler {
+	return &ReservationHandler{
+		Manager:              m,
+		ErrorResponseHandler: &errorlog.HTTPErrorResponseWriterAndLogger{Logger: errorLogger},
+	}
+}
+
+func (h *ReservationHandler) HandlePostReservation(ctx *gin.Context) {
+	var params types.NewReservationParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		appErr := errorlog.InternalServerError(errors.New("userID not found in context"))
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+	params.UserID = userID.(string)
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	reservation, err := h.Manager.AddNewReservation(ctx, params)
+	if err != nil {
+		appErr := bookingError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+func (h *ReservationHandler) HandleGetReservation(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	reservation, bookings, err := h.Manager.GetReservation(ctx, id)
+	if err != nil {
+		appErr := bookingError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"reservation": reservation, "bookings": bookings})
+}
+
+func (h *ReservationHandler) HandleCancelReservation(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	reservation, bookings, err := h.Manager.CancelReservation(ctx, id)
+	if err != nil {
+		appErr := bookingError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"reservation": reservation, "bookings": bookings})
+}
+
+func (h *ReservationHandler) HandleCancelReservationLine(ctx *gin.Context) {
+	id := ctx.Param("id")
+	bookingID := ctx.Param("bookingID")
+
+	reservation, bookings, err := h.Manager.CancelReservationLine(ctx, id, bookingID)
+	if err != nil {
+		appErr := bookingError(err)
+		if errors.Is(err, business.ErrBookingNotInReservation) {
+			appErr = errorlog.NotFoundError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"reservation": reservation, "bookings": bookings})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/errorlog"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+type RoomHandler struct {
+	Manager              *business.Manager
+	ErrorResponseHandler *errorlog.HTTPErrorResponseWriterAndLogger
+}
+
+func NewRoomHandler(m *business.Manager, errorLogger *log.Logger) *RoomHandler {
+	return &RoomHandler{
+		Manager:              m,
+		ErrorResponseHandler: &errorlog.HTTPErrorResponseWriterAndLogger{Logger: errorLogger},
+	}
+}
+
+func (h *RoomHandler) HandleGetRoomQuote(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var q types.DateRangeQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := q.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	quote, err := h.Manager.QuoteStay(ctx, id, q.From, q.Till)
+	if err != nil {
+		appErr := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrNotFound) {
+			appErr = errorlog.NotFoundError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+func (h *RoomHandler) HandlePutRoom(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var params types.NewRoomParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	room, err := h.Manager.ReplaceRoom(ctx, id, params)
+	if err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, room)
+}
+
+func (h *RoomHandler) HandlePatchRoom(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var params types.UpdateRoomParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		appErr := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	room, err := h.Manager.UpdateRoom(ctx, id, params)
+	if err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, room)
+}
+
+func (h *RoomHandler) HandleDeleteRoom(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := h.Manager.DeleteRoom(ctx, id); err != nil {
+		appErr := catalogError(err)
+		h.ErrorResponseHandler.LogAndHandleError(ctx.Writer, ctx.Writer, appErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "room has been deleted"})
+}
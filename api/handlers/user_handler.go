@@ -144,12 +144,270 @@ func (h *UserHandler) HandlePostUser(ctx *gin.Context) {
 		return
 	}
 
-	insertedUser, err := h.Manager.AddNewUser(ctx, params)
+	// The account stays pending until the user follows the emailed link
+	insertedUser, err := h.Manager.RegisterUser(ctx, params)
 	if err != nil {
 		httpError := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrEmailTaken) {
+			httpError = errorlog.ConflictError(err)
+			httpError.Details = err.Error()
+		}
 		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, insertedUser)
 }
+
+func (h *UserHandler) HandleSetUserRole(ctx *gin.Context) {
+	var params types.SetUserRoleParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	userID := ctx.Param("id")
+
+	user, err := h.Manager.SetUserRole(ctx, userID, params)
+	if err != nil {
+		httpError := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrNotFound) {
+			httpError = errorlog.NotFoundError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) HandleChangePassword(ctx *gin.Context) {
+	var params types.ChangePasswordParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	err := h.Manager.ChangePassword(ctx, ctx.Param("id"), params)
+	if err != nil {
+		var httpError errorlog.AppError
+		switch {
+		case errors.Is(err, types.ErrWrongPassword):
+			httpError = errorlog.ForbiddenError(err)
+		case errors.Is(err, types.ErrNotFound):
+			httpError = errorlog.NotFoundError(err)
+		default:
+			httpError = errorlog.InternalServerError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	// Every session was revoked, this one included
+	ctx.JSON(http.StatusOK, gin.H{"message": "password has been changed, please log in again"})
+}
+
+func (h *UserHandler) HandleSetAccountStatus(ctx *gin.Context) {
+	var params types.SetAccountStatusParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	user, canceled, err := h.Manager.SetAccountStatus(ctx, ctx.Param("id"), ctx.GetString("userID"), params)
+	if err != nil {
+		httpError := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrNotFound) {
+			httpError = errorlog.NotFoundError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"user": user, "canceledBookings": canceled})
+}
+
+// HandleUnlockAccount lifts a lockout after too many failed logins.
+func (h *UserHandler) HandleUnlockAccount(ctx *gin.Context) {
+	if err := h.Manager.UnlockAccount(ctx, ctx.Param("id")); err != nil {
+		httpError := errorlog.InternalServerError(err)
+		if errors.Is(err, types.ErrNotFound) {
+			httpError = errorlog.NotFoundError(err)
+		}
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "account unlocked"})
+}
+
+// HandleEnrollTOTP starts setting up TOTP for the logged in user.
+func (h *UserHandler) HandleEnrollTOTP(ctx *gin.Context) {
+	enrollment, err := h.Manager.EnrollTOTP(ctx, ctx.Param("id"))
+	if err != nil {
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+// HandleConfirmTOTP turns TOTP on and returns the recovery codes, once.
+func (h *UserHandler) HandleConfirmTOTP(ctx *gin.Context) {
+	var params types.MFACodeParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	codes, err := h.Manager.ConfirmTOTP(ctx, ctx.Param("id"), params)
+	if err != nil {
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+func (h *UserHandler) HandleRegenerateRecoveryCodes(ctx *gin.Context) {
+	var params types.MFACodeParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	codes, err := h.Manager.RegenerateRecoveryCodes(ctx, ctx.Param("id"), params)
+	if err != nil {
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+func (h *UserHandler) HandleDisableMFA(ctx *gin.Context) {
+	var params types.MFACodeParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := h.Manager.DisableMFA(ctx, ctx.Param("id"), params); err != nil {
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication has been disabled"})
+}
+
+// HandleResetMFA removes a user's second factor for them, as for a lost phone.
+func (h *UserHandler) HandleResetMFA(ctx *gin.Context) {
+	if err := h.Manager.ResetMFA(ctx, ctx.Param("id")); err != nil {
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, mfaError(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "two-factor authentication has been reset"})
+}
+
+func (h *UserHandler) HandleGetMFAPolicy(ctx *gin.Context) {
+	policy, err := h.Manager.GetMFAPolicy(ctx)
+	if err != nil {
+		httpError := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+func (h *UserHandler) HandleSetMFAPolicy(ctx *gin.Context) {
+	var params types.SetMFAPolicyParams
+
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	if err := params.Validate(); err != nil {
+		httpError := errorlog.BadRequestError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	policy, err := h.Manager.SetMFAPolicy(ctx, ctx.GetString("userID"), params)
+	if err != nil {
+		httpError := errorlog.InternalServerError(err)
+		h.ErrorResponseHandler.LogAndHandleError(os.Stdout, ctx.Writer, httpError)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+func mfaError(err error) errorlog.AppError {
+	var httpError errorlog.AppError
+	switch {
+	case errors.Is(err, types.ErrInvalidMFACode), errors.Is(err, types.ErrMFARequired):
+		httpError = errorlog.ForbiddenError(err)
+	case errors.Is(err, types.ErrMFANotEnrolled):
+		httpError = errorlog.BadRequestError(err)
+	case errors.Is(err, types.ErrMFAAlreadyEnabled):
+		httpError = errorlog.ConflictError(err)
+	case errors.Is(err, types.ErrNotFound):
+		return errorlog.NotFoundError(err)
+	default:
+		return errorlog.InternalServerError(err)
+	}
+	httpError.Details = err.Error()
+	return httpError
+}
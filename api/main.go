@@ -8,10 +8,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/notify"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -22,6 +25,21 @@ const (
 	hotelColl   = "hotels"
 	roomColl    = "rooms"
 	bookingColl = "bookings"
+
+	reservationColl = "reservations"
+	sessionColl     = "sessions"
+	userTokenColl   = "user_tokens"
+	outboxColl      = "outbox"
+
+	loginAttemptColl = "login_attempts"
+	settingsColl     = "settings"
+	apiKeyColl       = "api_keys"
+	oidcLoginColl    = "oidc_logins"
+)
+
+const (
+	storeMongo  = "mongo"
+	storeMemory = "memory"
 )
 
 const serverShutdownTimeout = 5 * time.Second
@@ -29,6 +47,22 @@ const serverShutdownTimeout = 5 * time.Second
 type config struct {
 	MONGODB_URI string `conf:"default:mongodb://localhost:27017,flag:dburi,env:DB_URI"`
 	Port        int    `conf:"default:8080,env:PORT"`
+	Store       string `conf:"default:mongo,flag:store,env:STORE"`
+
+	// Pending bookings that are not confirmed within BookingHoldTTL expire
+	BookingHoldTTL        time.Duration `conf:"default:30m,env:BOOKING_HOLD_TTL"`
+	BookingExpiryInterval time.Duration `conf:"default:1m,env:BOOKING_EXPIRY_INTERVAL"`
+
+	// PublicURL is where users reach the API, for links in notifications
+	PublicURL string `conf:"default:http://localhost:8080,env:PUBLIC_URL"`
+
+	// TrustedProxies may set X-Forwarded-For, which otherwise is ignored so
+	// clients cannot pick the IP their failed logins are counted against
+	TrustedProxies []string `conf:"env:TRUSTED_PROXIES"`
+
+	JWT    jwtConfig
+	Notify notifyConfig
+	OIDC   oidcConfig
 }
 
 func main() {
@@ -44,27 +78,81 @@ func main() {
 		}
 		log.Fatalf("Error parsing configuration: %v\n", err)
 	}
+	if cfg.BookingHoldTTL <= 0 || cfg.BookingExpiryInterval <= 0 {
+		log.Fatalf("Error parsing configuration: BOOKING_HOLD_TTL and BOOKING_EXPIRY_INTERVAL must be positive, got %s and %s\n", cfg.BookingHoldTTL, cfg.BookingExpiryInterval)
+	}
 
 	// DATABASE
-	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.MONGODB_URI))
+	var (
+		client  *mongo.Client
+		manager *business.Manager
+	)
+	switch cfg.Store {
+	case storeMongo:
+		client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(cfg.MONGODB_URI))
+		if err != nil {
+			log.Fatal(err)
+		}
+		manager, err = newMongoManager(context.Background(), client)
+		if err != nil {
+			log.Fatalf("Error setting up the database: %v\n", err)
+		}
+	case storeMemory:
+		manager = newMemoryManager()
+	default:
+		log.Fatalf("Unknown store %q, expected %q or %q\n", cfg.Store, storeMongo, storeMemory)
+	}
+
+	manager.Tokens, err = newTokenIssuer(cfg.JWT)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Error configuring JWT keys: %v\n", err)
+	}
+	manager.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+
+	manager.OIDCProviders, err = newOIDCProviders(cfg.OIDC, manager.PublicURL)
+	if err != nil {
+		log.Fatalf("Error configuring identity providers: %v\n", err)
+	}
+
+	sender, closeSender, err := newSender(cfg.Notify)
+	if err != nil {
+		log.Fatalf("Error configuring notifications: %v\n", err)
 	}
+	defer closeSender()
 
 	// SERVER
-	engine := setupRouter(client)
+	engine, err := setupRouter(manager, cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Error setting up router: %v\n", err)
+	}
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
 		Handler: engine,
 	}
 
-	err = run(cfg, client, server)
+	err = run(cfg, manager, sender, server)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-func run(cfg config, client *mongo.Client, server *http.Server) error {
+func run(cfg config, manager *business.Manager, sender notify.Sender, server *http.Server) error {
+
+	// WORKERS
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	errorLogger := log.New(os.Stderr, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
+	expiryDone := make(chan struct{})
+	go func() {
+		defer close(expiryDone)
+		manager.RunBookingExpiry(workersCtx, cfg.BookingHoldTTL, cfg.BookingExpiryInterval, errorLogger)
+	}()
+	notifyDone := make(chan struct{})
+	go func() {
+		defer close(notifyDone)
+		manager.RunNotificationDispatch(workersCtx, sender, cfg.Notify.Interval, errorLogger)
+	}()
 
 	chanErrors := make(chan error)
 	go func() {
@@ -86,6 +174,18 @@ func run(cfg config, client *mongo.Client, server *http.Server) error {
 			log.Fatal("Server forced to shutdown: ", err)
 			return err
 		}
+
+		stopWorkers()
+		select {
+		case <-expiryDone:
+		case <-ctx.Done():
+			log.Print("Booking expiry worker did not stop in time")
+		}
+		select {
+		case <-notifyDone:
+		case <-ctx.Done():
+			log.Print("Notification worker did not stop in time")
+		}
 		log.Print("Server exiting gracefully")
 	}
 	return nil
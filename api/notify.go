@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/notify"
+)
+
+const (
+	senderLog  = "log"
+	senderSMTP = "smtp"
+)
+
+// notifyConfig picks how notifications in the outbox are delivered: "log"
+// writes them to stdout, or to LogFile when set, and "smtp" mails them.
+type notifyConfig struct {
+	Sender   string        `conf:"default:log,env:NOTIFY_SENDER"`
+	Interval time.Duration `conf:"default:10s,env:NOTIFY_INTERVAL"`
+	LogFile  string        `conf:"env:NOTIFY_LOG_FILE"`
+
+	SMTPAddr     string        `conf:"env:SMTP_ADDR"`
+	SMTPFrom     string        `conf:"env:SMTP_FROM"`
+	SMTPUsername string        `conf:"env:SMTP_USERNAME"`
+	SMTPPassword string        `conf:"env:SMTP_PASSWORD,mask"`
+	SMTPTimeout  time.Duration `conf:"default:30s,env:SMTP_TIMEOUT"`
+}
+
+// newSender returns the configured sender and a function to release it.
+func newSender(cfg notifyConfig) (notify.Sender, func() error, error) {
+	if cfg.Interval <= 0 {
+		return nil, nil, fmt.Errorf("NOTIFY_INTERVAL must be positive, got %s", cfg.Interval)
+	}
+
+	switch cfg.Sender {
+	case senderLog:
+		if cfg.LogFile == "" {
+			return notify.NewWriterSender(os.Stdout), func() error { return nil }, nil
+		}
+		f, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, nil, err
+		}
+		return notify.NewWriterSender(f), f.Close, nil
+	case senderSMTP:
+		if cfg.SMTPAddr == "" || cfg.SMTPFrom == "" {
+			return nil, nil, fmt.Errorf("SMTP_ADDR and SMTP_FROM are required for the smtp sender")
+		}
+		sender := &notify.SMTPSender{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			Timeout:  cfg.SMTPTimeout,
+		}
+		return sender, func() error { return nil }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown sender %q, expected %q or %q", cfg.Sender, senderLog, senderSMTP)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mkabdelrahman/hotel-reservation/oidc"
+)
+
+// oidcConfig names a JSON file listing the identity providers users may log
+// in with, like
+//
+//	[{"name": "acme", "issuer": "https://login.acme.com", "client_id": "...", "client_secret": "..."}]
+//
+// Each provider must allow PUBLIC_URL/api/auth/oidc/<name>/callback as a
+// redirect URL.
+type oidcConfig struct {
+	ProvidersFile string `conf:"env:OIDC_PROVIDERS_FILE"`
+}
+
+type oidcProviderConfig struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"`
+}
+
+func newOIDCProviders(cfg oidcConfig, publicURL string) (map[string]*oidc.Provider, error) {
+	providers := make(map[string]*oidc.Provider)
+	if cfg.ProvidersFile == "" {
+		return providers, nil
+	}
+
+	data, err := os.ReadFile(cfg.ProvidersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []oidcProviderConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.ProvidersFile, err)
+	}
+
+	for _, c := range configs {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: provider name is required", cfg.ProvidersFile)
+		}
+		if _, ok := providers[c.Name]; ok {
+			return nil, fmt.Errorf("%s: duplicate provider %s", cfg.ProvidersFile, c.Name)
+		}
+
+		provider, err := oidc.NewProvider(oidc.Config{
+			Issuer:       c.Issuer,
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			RedirectURL:  publicURL + "/api/auth/oidc/" + c.Name + "/callback",
+			Scopes:       c.Scopes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", c.Name, err)
+		}
+		providers[c.Name] = provider
+	}
+	return providers, nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"os"
 
@@ -8,18 +10,42 @@ import (
 	"github.com/mkabdelrahman/hotel-reservation/api/handlers"
 	"github.com/mkabdelrahman/hotel-reservation/business"
 	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/db/memory"
 	"github.com/mkabdelrahman/hotel-reservation/middleware"
+	"github.com/mkabdelrahman/hotel-reservation/types"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func setupRouter(client *mongo.Client) *gin.Engine {
-
+func newMongoManager(ctx context.Context, client *mongo.Client) (*business.Manager, error) {
 	userStore := db.NewMongoUserStore(client, dbName, userColl)
-	hotelStore := db.NewMongoHotelStore(client, dbName, hotelColl)
-	roomStore := db.NewMongoRoomStore(client, dbName, roomColl)
-	bookingStore := db.NewMongoBookingStore(client, dbName, bookingColl)
+	if err := userStore.EnsureIndexes(ctx); err != nil {
+		return nil, fmt.Errorf("indexing %s: %w", userColl, err)
+	}
 
-	hotelManager := business.NewManager(userStore, hotelStore, roomStore, bookingStore)
+	return business.NewManager(db.Stores{
+		HotelStore:   db.NewMongoHotelStore(client, dbName, hotelColl, roomColl, bookingColl),
+		RoomStore:    db.NewMongoRoomStore(client, dbName, roomColl),
+		UserStore:    userStore,
+		BookingStore: db.NewMongoBookingStore(client, dbName, bookingColl),
+
+		ReservationStore: db.NewMongoReservationStore(client, dbName, reservationColl),
+		SessionStore:     db.NewMongoSessionStore(client, dbName, sessionColl),
+		UserTokenStore:   db.NewMongoUserTokenStore(client, dbName, userTokenColl),
+		OutboxStore:      db.NewMongoOutboxStore(client, dbName, outboxColl),
+
+		LoginAttemptStore: db.NewMongoLoginAttemptStore(client, dbName, loginAttemptColl),
+		SettingsStore:     db.NewMongoSettingsStore(client, dbName, settingsColl),
+		APIKeyStore:       db.NewMongoAPIKeyStore(client, dbName, apiKeyColl),
+		OIDCLoginStore:    db.NewMongoOIDCLoginStore(client, dbName, oidcLoginColl),
+	}), nil
+}
+
+// newMemoryManager keeps all data in process memory; it is lost on restart.
+func newMemoryManager() *business.Manager {
+	return business.NewManager(memory.NewStores())
+}
+
+func setupRouter(hotelManager *business.Manager, trustedProxies []string) (*gin.Engine, error) {
 
 	// logger
 
@@ -28,35 +54,95 @@ func setupRouter(client *mongo.Client) *gin.Engine {
 	authHandler := handlers.NewAuthHandler(hotelManager, errorLogger)
 	userHandler := handlers.NewUserHandler(hotelManager, errorLogger)
 	hotelHandler := handlers.NewHotelHandler(hotelManager, errorLogger)
+	roomHandler := handlers.NewRoomHandler(hotelManager, errorLogger)
 	bookingHandler := handlers.NewBookingHandler(hotelManager, errorLogger)
+	reservationHandler := handlers.NewReservationHandler(hotelManager, errorLogger)
+	apiKeyHandler := handlers.NewAPIKeyHandler(hotelManager, errorLogger)
 
 	engine := gin.New()
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
 
 	engine.Use(middleware.Logger)
 	engine.Use(gin.Recovery())
 
 	v1 := engine.Group("/api/v1")
 
-	// v1.Use(middleware.AuthMiddleware())
+	// Every v1 route but registration and hotel browsing needs a token
+	authed := v1.Group("", middleware.AuthMiddleware(hotelManager))
+
+	selfOrUserManager := middleware.SelfOrPermissionMiddleware("id", types.PermManageUsers)
+	bookingViewer := middleware.BookingOwnerMiddleware(hotelManager, "id", types.PermViewBookings)
+	bookingManager := middleware.BookingOwnerMiddleware(hotelManager, "id", types.PermManageBookings)
+	reservationManager := middleware.ReservationOwnerMiddleware(hotelManager, "id", types.PermManageBookings)
+
+	manageUsers := middleware.RequirePermission(types.PermManageUsers, nil)
+	manageHotels := middleware.RequirePermission(types.PermManageHotels, nil)
+	manageHotelRooms := middleware.RequirePermission(types.PermManageRooms, middleware.HotelParam("id"))
+	manageRoom := middleware.RequirePermission(types.PermManageRooms, middleware.RoomHotel(hotelManager, "id"))
 
-	adminRoutes := engine.Group("/admin", middleware.AdminOnlyMiddleware(hotelManager))
+	adminRoutes := engine.Group("/admin", middleware.AuthMiddleware(hotelManager))
 
 	{
-		adminRoutes.GET("/dashboard", func(c *gin.Context) {
+		adminRoutes.GET("/dashboard", manageUsers, func(c *gin.Context) {
 			c.JSON(200, gin.H{"message": "Admin dashboard"})
 		})
+
+		// users
+		adminRoutes.PUT("/user/:id/role", manageUsers, userHandler.HandleSetUserRole)
+		adminRoutes.PUT("/user/:id/status", manageUsers, userHandler.HandleSetAccountStatus)
+		adminRoutes.POST("/user/:id/unlock", manageUsers, userHandler.HandleUnlockAccount)
+		adminRoutes.DELETE("/user/:id/mfa", manageUsers, userHandler.HandleResetMFA)
+		adminRoutes.GET("/mfa/policy", manageUsers, userHandler.HandleGetMFAPolicy)
+		adminRoutes.PUT("/mfa/policy", manageUsers, userHandler.HandleSetMFAPolicy)
+
+		// An API key may not issue more keys
+		userOnly := middleware.UserOnlyMiddleware()
+		adminRoutes.POST("/api-keys", userOnly, manageUsers, apiKeyHandler.HandlePostAPIKey)
+		adminRoutes.GET("/api-keys", userOnly, manageUsers, apiKeyHandler.HandleGetAPIKeys)
+		adminRoutes.DELETE("/api-keys/:id", userOnly, manageUsers, apiKeyHandler.HandleRevokeAPIKey)
+
+		// hotels and rooms
+		adminRoutes.POST("/hotel", manageHotels, hotelHandler.HandlePostHotel)
+		adminRoutes.PUT("/hotel/:id", manageHotelRooms, hotelHandler.HandlePutHotel)
+		adminRoutes.PATCH("/hotel/:id", manageHotelRooms, hotelHandler.HandlePatchHotel)
+		adminRoutes.DELETE("/hotel/:id", manageHotels, hotelHandler.HandleDeleteHotel)
+		adminRoutes.POST("/hotel/:id/rooms", manageHotelRooms, hotelHandler.HandlePostHotelRoom)
+
+		adminRoutes.PUT("/room/:id", manageRoom, roomHandler.HandlePutRoom)
+		adminRoutes.PATCH("/room/:id", manageRoom, roomHandler.HandlePatchRoom)
+		adminRoutes.DELETE("/room/:id", manageRoom, roomHandler.HandleDeleteRoom)
 	}
 
 	engine.POST("/api/auth", authHandler.HandleAuthenticate)
+	engine.POST("/api/auth/mfa", authHandler.HandleCompleteMFALogin)
+	engine.POST("/api/auth/mfa/enroll", authHandler.HandleEnrollMFA)
+	engine.POST("/api/auth/refresh", authHandler.HandleRefresh)
+	engine.GET("/api/auth/oidc/:provider", authHandler.HandleStartOIDCLogin)
+	engine.GET("/api/auth/oidc/:provider/callback", authHandler.HandleOIDCCallback)
+	engine.POST("/api/auth/logout", middleware.AuthMiddleware(hotelManager), middleware.UserOnlyMiddleware(), authHandler.HandleLogout)
+	engine.GET("/api/auth/verify", authHandler.HandleVerifyEmail)
+	engine.POST("/api/auth/verify/resend", authHandler.HandleResendVerification)
+	engine.POST("/api/auth/password/forgot", authHandler.HandleRequestPasswordReset)
+	engine.POST("/api/auth/password/reset", authHandler.HandleResetPassword)
+	engine.GET("/.well-known/jwks.json", authHandler.HandleJWKS)
 
 	// users
-	v1.GET("/user/:id", userHandler.HandleGetUser)
-	v1.DELETE("/user/:id", userHandler.HandleDeleteUser)
-	v1.GET("/user/:id/bookings", userHandler.HandleGetUserBookings)
+	authed.GET("/user/:id", selfOrUserManager, userHandler.HandleGetUser)
+	authed.DELETE("/user/:id", selfOrUserManager, userHandler.HandleDeleteUser)
+	authed.GET("/user/:id/bookings", selfOrUserManager, userHandler.HandleGetUserBookings)
 
-	v1.GET("/user", userHandler.HandleGetUsers)
+	authed.GET("/user", manageUsers, userHandler.HandleGetUsers)
 	v1.POST("/user", userHandler.HandlePostUser)
-	v1.PUT("/user/:id", userHandler.HandleUpdateUser)
+	authed.PUT("/user/:id", selfOrUserManager, userHandler.HandleUpdateUser)
+	authed.PUT("/user/:id/password", middleware.SelfOnlyMiddleware("id"), userHandler.HandleChangePassword)
+
+	self := middleware.SelfOnlyMiddleware("id")
+	authed.POST("/user/:id/mfa/totp", self, userHandler.HandleEnrollTOTP)
+	authed.POST("/user/:id/mfa/totp/confirm", self, userHandler.HandleConfirmTOTP)
+	authed.POST("/user/:id/mfa/recovery-codes", self, userHandler.HandleRegenerateRecoveryCodes)
+	authed.DELETE("/user/:id/mfa", self, userHandler.HandleDisableMFA)
 
 	// hotel
 	v1.GET("/hotel", hotelHandler.HandleGetHotels)
@@ -65,15 +151,37 @@ func setupRouter(client *mongo.Client) *gin.Engine {
 
 	v1.GET("/hotel/:id/rooms", hotelHandler.HandleGetHotelRooms)
 
+	v1.GET("/hotel/:id/availability", hotelHandler.HandleGetHotelAvailability)
+
 	v1.GET("/hotel/search", hotelHandler.HandleHotelSearch)
 
+	// room
+
+	v1.GET("/room/:id/quote", roomHandler.HandleGetRoomQuote)
+
 	// booking
 
-	v1.GET("/booking/:id", bookingHandler.HandleGetBooking)
-	v1.GET("/booking", bookingHandler.HandleGetBookings)
-	v1.POST("/booking", bookingHandler.HandlePostBooking)
+	authed.GET("/booking/:id", bookingViewer, bookingHandler.HandleGetBooking)
+	authed.GET("/booking", middleware.RequirePermission(types.PermViewBookings, nil), bookingHandler.HandleGetBookings)
+	createBookings := middleware.RequirePermission(types.PermCreateBookings, nil)
+	authed.POST("/booking", createBookings, bookingHandler.HandlePostBooking)
+	authed.PATCH("/booking/:id", bookingManager, bookingHandler.HandleModifyBooking)
 	// used to change the booking status
-	v1.DELETE("/booking/:id", bookingHandler.HandleCancelBooking)
+	authed.DELETE("/booking/:id", bookingManager, bookingHandler.HandleCancelBooking)
+
+	// reservation, a group of bookings sharing dates and guest
+
+	authed.GET("/reservation/:id", reservationManager, reservationHandler.HandleGetReservation)
+	authed.POST("/reservation", createBookings, reservationHandler.HandlePostReservation)
+	authed.DELETE("/reservation/:id", reservationManager, reservationHandler.HandleCancelReservation)
+	authed.DELETE("/reservation/:id/booking/:bookingID", reservationManager, reservationHandler.HandleCancelReservationLine)
+
+	// booking lifecycle, for staff of the booking's hotel
+	bookingStaffRoutes := authed.Group("/booking", middleware.RequirePermission(types.PermManageBookings, middleware.BookingHotel(hotelManager, "id")))
+	bookingStaffRoutes.POST("/:id/confirm", bookingHandler.HandleConfirmBooking)
+	bookingStaffRoutes.POST("/:id/check-in", bookingHandler.HandleCheckInBooking)
+	bookingStaffRoutes.POST("/:id/check-out", bookingHandler.HandleCheckOutBooking)
+	bookingStaffRoutes.POST("/:id/no-show", bookingHandler.HandleNoShowBooking)
 
-	return engine
+	return engine, nil
 }
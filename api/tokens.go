@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+)
+
+// legacyKeyID names the key read from JWT_SECRET when no keys are listed.
+const legacyKeyID = "default"
+
+// jwtConfig lists the keys access tokens are checked against, by kid. To
+// rotate, add a new key, make it the signing key and drop the old one once
+// the access tokens it signed have expired.
+type jwtConfig struct {
+	Issuer         string        `conf:"default:hotel-reservation,env:JWT_ISSUER"`
+	Audience       string        `conf:"default:hotel-reservation-api,env:JWT_AUDIENCE"`
+	AccessTokenTTL time.Duration `conf:"default:15m,env:JWT_ACCESS_TOKEN_TTL"`
+
+	// Keys are HS256 secrets as "kid:secret;kid:secret"
+	Keys map[string]string `conf:"env:JWT_KEYS,mask"`
+	// KeyFiles are PEM RSA or Ed25519 keys as "kid:path;kid:path"; public
+	// keys only check tokens, private keys may also sign them
+	KeyFiles   map[string]string `conf:"env:JWT_KEY_FILES"`
+	SigningKey string            `conf:"env:JWT_SIGNING_KEY"`
+
+	// Secret is used, with kid "default", when no keys are listed
+	Secret string `conf:"env:JWT_SECRET,mask"`
+}
+
+func newTokenIssuer(cfg jwtConfig) (*auth.Issuer, error) {
+	var keys []auth.Key
+
+	for kid, secret := range cfg.Keys {
+		key, err := auth.NewHMACKey(kid, []byte(secret))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	for kid, path := range cfg.KeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", kid, err)
+		}
+		key, err := auth.ParsePEMKey(kid, data)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("no keys configured, set JWT_KEYS, JWT_KEY_FILES or JWT_SECRET")
+		}
+		key, err := auth.NewHMACKey(legacyKeyID, []byte(cfg.Secret))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return auth.NewIssuer(auth.Config{
+		Issuer:         cfg.Issuer,
+		Audience:       cfg.Audience,
+		AccessTokenTTL: cfg.AccessTokenTTL,
+		Keys:           keys,
+		SigningKeyID:   cfg.SigningKey,
+	})
+}
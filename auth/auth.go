@@ -1,26 +1,137 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt"
 )
 
-const expirationPeriod = time.Hour * 24
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims are the claims of an access token. The user ID is the subject and
+// the token ID (jti) names the token within its session (sid).
+type Claims struct {
+	jwt.StandardClaims
+	Role      string   `json:"role,omitempty"`
+	HotelIDs  []string `json:"hotel_ids,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+}
+
+type Config struct {
+	Issuer   string
+	Audience string
+
+	// AccessTokenTTL is kept short since a token is trusted until it expires
+	// or its session is revoked; clients renew it with their refresh token.
+	AccessTokenTTL time.Duration
+
+	// Keys check tokens by their "kid"; SigningKeyID picks the key new tokens
+	// are signed with and may be left empty when there is only one key.
+	Keys         []Key
+	SigningKeyID string
+}
+
+// Issuer signs and parses access tokens and link tokens. Adding a new key as
+// the signing key and keeping the old one until its tokens expire rotates
+// keys without logging anyone out.
+type Issuer struct {
+	issuer   string
+	audience string
+	ttl      time.Duration
+
+	keys       map[string]Key
+	signingKey Key
+	jwks       JWKSet
+}
+
+func NewIssuer(cfg Config) (*Issuer, error) {
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, errors.New("issuer and audience are required")
+	}
+	if cfg.AccessTokenTTL <= 0 {
+		return nil, errors.New("access token TTL must be positive")
+	}
+	if len(cfg.Keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	i := &Issuer{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		ttl:      cfg.AccessTokenTTL,
+		keys:     make(map[string]Key, len(cfg.Keys)),
+		jwks:     JWKSet{Keys: []JWK{}},
+	}
+	for _, key := range cfg.Keys {
+		if _, ok := i.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %s", key.ID)
+		}
+		i.keys[key.ID] = key
+		if jwk, ok := key.jwk(); ok {
+			i.jwks.Keys = append(i.jwks.Keys, jwk)
+		}
+	}
+
+	signingKeyID := cfg.SigningKeyID
+	if signingKeyID == "" {
+		if len(cfg.Keys) > 1 {
+			return nil, errors.New("signing key ID is required when there are several keys")
+		}
+		signingKeyID = cfg.Keys[0].ID
+	}
+	signingKey, ok := i.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not configured", signingKeyID)
+	}
+	if !signingKey.CanSign() {
+		return nil, fmt.Errorf("signing key %s is a public key", signingKeyID)
+	}
+	i.signingKey = signingKey
+
+	return i, nil
+}
+
+func (i *Issuer) AccessTokenTTL() time.Duration {
+	return i.ttl
+}
+
+// JWKS returns the public keys tokens may be signed with, for clients that
+// check tokens themselves. HMAC keys are secret and never listed.
+func (i *Issuer) JWKS() JWKSet {
+	return i.jwks
+}
 
-func GenerateAuthToken(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"id":      userID,
-		"expires": time.Now().Add(expirationPeriod).Unix(),
-		"issued":  time.Now().Unix(),
+// GenerateAuthToken issues an access token for the user carrying their role
+// and, for roles scoped to hotels, the hotel IDs. sessionID and tokenID let
+// the session it belongs to revoke it.
+func (i *Issuer) GenerateAuthToken(userID string, role string, hotelIDs []string, sessionID string, tokenID string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   userID,
+			Issuer:    i.issuer,
+			Audience:  i.audience,
+			Id:        tokenID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(i.ttl).Unix(),
+		},
+		Role:      role,
+		HotelIDs:  hotelIDs,
+		SessionID: sessionID,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secretKey := []byte(os.Getenv("JWT_SECRET"))
+	token := jwt.NewWithClaims(i.signingKey.Method, claims)
+	token.Header["kid"] = i.signingKey.ID
 
-	tokenString, err := token.SignedString(secretKey)
+	tokenString, err := token.SignedString(i.signingKey.signKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %v", err)
 	}
@@ -28,19 +139,102 @@ func GenerateAuthToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
-func IsTokenNotExpired(token *jwt.Token) bool {
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		expirationTime := int64(claims["expires"].(float64))
-		return time.Now().Unix() < expirationTime
+// ParseToken checks the token's signature, issuer, audience and expiry. It
+// returns ErrTokenExpired for an expired token and ErrInvalidToken for any
+// other problem.
+func (i *Issuer) ParseToken(tokenString string) (*Claims, error) {
+	var claims Claims
+	if err := i.parse(tokenString, &claims); err != nil {
+		return nil, err
+	}
+
+	// StandardClaims.Valid accepts tokens without these claims
+	if claims.ExpiresAt == 0 || claims.Subject == "" ||
+		!claims.VerifyIssuer(i.issuer, true) || !claims.VerifyAudience(i.audience, true) {
+		return nil, ErrInvalidToken
 	}
-	return false
+
+	return &claims, nil
 }
 
-func ParseToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// parse checks the token's signature against the key named by its kid, and
+// its time claims.
+func (i *Issuer) parse(tokenString string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := i.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q", kid)
+		}
+		// The key, not the token, decides the algorithm
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(os.Getenv("JWT_SECRET")), nil
+		return key.verifyKey, nil
 	})
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return ErrTokenExpired
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return nil
+}
+
+// SignLinkToken returns a token for a link sent to the user, good for one
+// purpose until ttl passes. The purpose is its audience, so it is never taken
+// for an access token, nor an access token for it.
+func (i *Issuer) SignLinkToken(purpose string, userID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.StandardClaims{
+		Subject:   userID,
+		Issuer:    i.issuer,
+		Audience:  purpose,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+
+	token := jwt.NewWithClaims(i.signingKey.Method, claims)
+	token.Header["kid"] = i.signingKey.ID
+
+	tokenString, err := token.SignedString(i.signingKey.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %v", err)
+	}
+
+	return tokenString, nil
+}
+
+// ParseLinkToken checks a token from SignLinkToken for the purpose and
+// returns the user ID it was signed for.
+func (i *Issuer) ParseLinkToken(purpose string, tokenString string) (string, error) {
+	var claims jwt.StandardClaims
+	if err := i.parse(tokenString, &claims); err != nil {
+		return "", err
+	}
+
+	if claims.ExpiresAt == 0 || claims.Subject == "" ||
+		!claims.VerifyIssuer(i.issuer, true) || !claims.VerifyAudience(purpose, true) {
+		return "", ErrInvalidToken
+	}
+
+	return claims.Subject, nil
+}
+
+// NewRandomToken returns n random bytes, hex encoded, for token IDs and
+// refresh tokens.
+func NewRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken returns the SHA-256 of a refresh token, as stored server-side.
+// Refresh tokens are long and random, so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
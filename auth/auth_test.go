@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestIssuer(t *testing.T, signingKeyID string, keys ...Key) *Issuer {
+	t.Helper()
+
+	issuer, err := NewIssuer(Config{Issuer: "hotel-reservation", Audience: "hotel-reservation", AccessTokenTTL: time.Minute, Keys: keys, SigningKeyID: signingKeyID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issuer
+}
+
+func newTestHMACKey(t *testing.T, id string) Key {
+	t.Helper()
+
+	key, err := NewHMACKey(id, []byte("secret of "+id))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestParseTokenClaims(t *testing.T) {
+	issuer := newTestIssuer(t, "", newTestHMACKey(t, "k1"))
+
+	token, err := issuer.GenerateAuthToken("user", "staff", []string{"hotel"}, "session", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := issuer.ParseToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Subject != "user" || claims.Issuer != "hotel-reservation" || claims.Audience != "hotel-reservation" || claims.Id != "token" {
+		t.Fatalf("got sub %q, iss %q, aud %q and jti %q", claims.Subject, claims.Issuer, claims.Audience, claims.Id)
+	}
+	if claims.ExpiresAt-claims.IssuedAt != int64(time.Minute/time.Second) {
+		t.Fatalf("got a lifetime of %ds, want %s", claims.ExpiresAt-claims.IssuedAt, time.Minute)
+	}
+	if claims.Role != "staff" || claims.SessionID != "session" || len(claims.HotelIDs) != 1 {
+		t.Fatalf("got role %q, session %q and hotels %v", claims.Role, claims.SessionID, claims.HotelIDs)
+	}
+}
+
+func TestParseTokenKeyRotation(t *testing.T) {
+	oldKey, newKey := newTestHMACKey(t, "old"), newTestHMACKey(t, "new")
+
+	oldToken, err := newTestIssuer(t, "", oldKey).GenerateAuthToken("user", "guest", nil, "session", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// While both keys are configured, tokens of either are accepted
+	rotating := newTestIssuer(t, "new", oldKey, newKey)
+	if _, err := rotating.ParseToken(oldToken); err != nil {
+		t.Fatalf("token of the old key: %v", err)
+	}
+	newToken, err := rotating.GenerateAuthToken("user", "guest", nil, "session", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the old key is dropped, only its tokens are refused
+	rotated := newTestIssuer(t, "", newKey)
+	if _, err := rotated.ParseToken(newToken); err != nil {
+		t.Fatalf("token of the new key: %v", err)
+	}
+	if _, err := rotated.ParseToken(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token of the dropped key: got %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestParseLinkToken(t *testing.T) {
+	issuer := newTestIssuer(t, "", newTestHMACKey(t, "k1"))
+
+	token, err := issuer.SignLinkToken("verify_email", "user", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID, err := issuer.ParseLinkToken("verify_email", token); err != nil || userID != "user" {
+		t.Fatalf("got %q, %v, want the user", userID, err)
+	}
+	if _, err := issuer.ParseLinkToken("password_reset", token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("other purpose: got %v, want %v", err, ErrInvalidToken)
+	}
+	if _, err := issuer.ParseToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("as an access token: got %v, want %v", err, ErrInvalidToken)
+	}
+
+	expired, err := issuer.SignLinkToken("verify_email", "user", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuer.ParseLinkToken("verify_email", expired); !errors.Is(err, ErrTokenExpired) {
+		t.Fatalf("expired: got %v, want %v", err, ErrTokenExpired)
+	}
+}
+
+func TestEd25519Key(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signing, err := ParsePEMKey("ed", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifying, err := ParsePEMKey("ed", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := newTestIssuer(t, "", signing)
+	if jwks := issuer.JWKS(); len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "ed" || jwks.Keys[0].Alg != "EdDSA" {
+		t.Fatalf("got JWKS %+v, want the Ed25519 key", jwks)
+	}
+	token, err := issuer.GenerateAuthToken("user", "guest", nil, "session", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A retired key is kept as its public half, which checks but cannot sign
+	if _, err := NewIssuer(Config{Issuer: "hotel-reservation", Audience: "hotel-reservation", AccessTokenTTL: time.Minute, Keys: []Key{verifying}}); err == nil {
+		t.Fatal("got an issuer signing with a public key")
+	}
+	retired := newTestIssuer(t, "k1", verifying, newTestHMACKey(t, "k1"))
+	if _, err := retired.ParseToken(token); err != nil {
+		t.Fatalf("token of the retired key: %v", err)
+	}
+}
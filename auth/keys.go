@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// A Key signs and checks tokens whose "kid" header is its ID. A key parsed
+// from a public key only checks tokens, which is how a retired asymmetric key
+// is kept until the tokens it signed have expired.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey returns an HS256 key. The secret should be at least 32 random
+// bytes.
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if id == "" {
+		return Key{}, errors.New("key ID is required")
+	}
+	if len(secret) == 0 {
+		return Key{}, fmt.Errorf("key %s: HMAC secret is empty", id)
+	}
+
+	return Key{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// ParsePEMKey reads an RSA (RS256) or Ed25519 (EdDSA) key, private or public.
+func ParsePEMKey(id string, data []byte) (Key, error) {
+	if id == "" {
+		return Key{}, errors.New("key ID is required")
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("key %s: no PEM block found", id)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			return Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: key}, nil
+		}
+		key, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s: %w", id, err)
+		}
+		return Key{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: key}, nil
+	default:
+		if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			return Key{ID: id, Method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey}, nil
+		}
+		key, err := jwt.ParseEdPrivateKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s: %w", id, err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return Key{}, fmt.Errorf("key %s: unsupported private key type", id)
+		}
+		return Key{ID: id, Method: jwt.SigningMethodEdDSA, signKey: edKey, verifyKey: edKey.Public()}, nil
+	}
+}
+
+func (k Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// jwk returns the public half of the key; HMAC keys have none.
+func (k Key) jwk() (JWK, bool) {
+	switch key := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: k.ID,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors,
+// "12345678901234567890".
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; ours are their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("at %d: got %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	issued := time.Unix(1111111111, 0)
+	code, err := TOTPCode(rfc6238Secret, issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := totpStep(issued)
+
+	tests := []struct {
+		name   string
+		code   string
+		now    time.Time
+		wantOK bool
+	}{
+		{"same step", code, issued, true},
+		{"one step late", code, issued.Add(totpPeriod), true},
+		{"one step early", code, issued.Add(-totpPeriod), true},
+		{"two steps late", code, issued.Add(2 * totpPeriod), false},
+		{"two steps early", code, issued.Add(-2 * totpPeriod), false},
+		{"wrong code", "000000", issued, false},
+		{"too short", code[:5], issued, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(rfc6238Secret, tt.code, tt.now)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %v, want %v", ok, tt.wantOK)
+			}
+			if ok && gotStep != step {
+				t.Fatalf("got step %d, want the issuing step %d", gotStep, step)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+const (
+	// apiKeyPrefix marks API keys, so a leaked one is easy to recognise
+	apiKeyPrefix = "hrk_"
+	// apiKeyTouchInterval limits how often using a key is written down
+	apiKeyTouchInterval = time.Minute
+)
+
+// CreateAPIKey issues a key for the user, limited to the scopes, which their
+// role must grant. The key itself is returned only this once.
+func (m *Manager) CreateAPIKey(ctx context.Context, createdBy string, params types.NewAPIKeyParams) (*types.NewAPIKey, error) {
+	user, err := m.UserStore.GetUserByID(ctx, params.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := user.EffectiveRole()
+	for _, scope := range params.Scopes {
+		if !role.Has(scope) {
+			return nil, fmt.Errorf("%w: role %s does not grant %s", types.ErrScopeNotGranted, role, scope)
+		}
+	}
+
+	secret, err := auth.NewRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := &types.APIKey{
+		Name:      params.Name,
+		UserID:    params.UserID,
+		Scopes:    params.Scopes,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		KeyHash:   auth.HashToken(key),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: params.ExpiresAt,
+	}
+	if _, err := m.APIKeyStore.InsertAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &types.NewAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys returns the keys of the user, or all keys if userID is empty.
+func (m *Manager) ListAPIKeys(ctx context.Context, userID string) ([]*types.APIKey, error) {
+	return m.APIKeyStore.GetAPIKeys(ctx, userID)
+}
+
+func (m *Manager) RevokeAPIKey(ctx context.Context, keyID string) error {
+	return m.APIKeyStore.RevokeAPIKey(ctx, keyID, time.Now().UTC())
+}
+
+// AuthenticateAPIKey returns the active key and its user, who must still be
+// able to log in. Keys act with their user's current role, so a key never
+// does more than its user may.
+func (m *Manager) AuthenticateAPIKey(ctx context.Context, key string) (*types.APIKey, *types.User, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, nil, types.ErrInvalidAPIKey
+	}
+
+	apiKey, err := m.APIKeyStore.GetAPIKeyByHash(ctx, auth.HashToken(key))
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, nil, types.ErrInvalidAPIKey
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now().UTC()
+	if !apiKey.IsActive(now) {
+		return nil, nil, types.ErrInvalidAPIKey
+	}
+
+	user, err := m.UserStore.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, nil, types.ErrInvalidAPIKey
+		}
+		return nil, nil, err
+	}
+	if err := user.EffectiveStatus().CanLogIn(); err != nil {
+		return nil, nil, err
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := m.APIKeyStore.TouchAPIKey(ctx, apiKey.ID, now); err != nil {
+			return nil, nil, err
+		}
+		apiKey.LastUsedAt = &now
+	}
+
+	return apiKey, user, nil
+}
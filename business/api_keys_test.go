@@ -0,0 +1,44 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestCreateAPIKeyNeedsRoleForScopes(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	userID, err := m.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Guests may book but not see others' bookings
+	_, err = m.CreateAPIKey(ctx, userID, types.NewAPIKeyParams{Name: "reports", UserID: userID, Scopes: []types.Permission{types.PermViewBookings}})
+	if !errors.Is(err, types.ErrScopeNotGranted) {
+		t.Fatalf("got %v, want %v", err, types.ErrScopeNotGranted)
+	}
+
+	key, err := m.CreateAPIKey(ctx, userID, types.NewAPIKeyParams{Name: "bookings", UserID: userID, Scopes: []types.Permission{types.PermCreateBookings}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKey, user, err := m.AuthenticateAPIKey(ctx, key.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiKey.ID != key.APIKey.ID || user.ID.Hex() != userID {
+		t.Fatalf("got key %s of user %s, want key %s of user %s", apiKey.ID, user.ID.Hex(), key.APIKey.ID, userID)
+	}
+
+	if err := m.RevokeAPIKey(ctx, key.APIKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.AuthenticateAPIKey(ctx, key.Key); !errors.Is(err, types.ErrInvalidAPIKey) {
+		t.Fatalf("revoked key: got %v, want %v", err, types.ErrInvalidAPIKey)
+	}
+}
@@ -0,0 +1,93 @@
+package business
+
+import (
+	"context"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// CheckAvailability lists, for every room of the hotel that sleeps at least
+// guests guests, the nights between from and till that are not held by an
+// active booking.
+func (m *Manager) CheckAvailability(ctx context.Context, hotelID string, from, till time.Time, guests int) ([]types.RoomAvailability, error) {
+	// Make sure the hotel exists
+	if _, err := m.HotelStore.GetHotel(ctx, hotelID); err != nil {
+		return nil, err
+	}
+
+	rooms, err := m.RoomStore.GetRoomsByHotelID(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	nights := types.Nights(from, till)
+
+	availability := make([]types.RoomAvailability, 0, len(rooms))
+	for _, room := range rooms {
+		if room.Capacity() < guests {
+			continue
+		}
+
+		freeNights, err := m.freeNights(ctx, room.ID, nights, "")
+		if err != nil {
+			return nil, err
+		}
+
+		availability = append(availability, types.RoomAvailability{
+			RoomID:     room.ID,
+			Number:     room.Number,
+			Type:       room.Type,
+			Price:      room.Price,
+			Capacity:   room.Capacity(),
+			FreeNights: freeNights,
+			Available:  len(freeNights) == len(nights),
+		})
+	}
+
+	return availability, nil
+}
+
+// freeNights returns the nights, formatted with types.DateLayout, on which the
+// room is not held by an active booking other than excludeBookingID.
+func (m *Manager) freeNights(ctx context.Context, roomID string, nights []time.Time, excludeBookingID string) ([]string, error) {
+	free, _, err := m.splitNights(ctx, roomID, nights, excludeBookingID)
+	return free, err
+}
+
+// bookedNights returns the nights, formatted with types.DateLayout, on which
+// the room is held by an active booking other than excludeBookingID.
+func (m *Manager) bookedNights(ctx context.Context, roomID string, nights []time.Time, excludeBookingID string) ([]string, error) {
+	_, booked, err := m.splitNights(ctx, roomID, nights, excludeBookingID)
+	return booked, err
+}
+
+func (m *Manager) splitNights(ctx context.Context, roomID string, nights []time.Time, excludeBookingID string) (free, booked []string, err error) {
+	free, booked = []string{}, []string{}
+	if len(nights) == 0 {
+		return free, booked, nil
+	}
+
+	from, till := nights[0], nights[len(nights)-1].AddDate(0, 0, 1)
+	bookings, err := m.BookingStore.GetActiveBookingsByRoomAndTimeRange(ctx, roomID, from, till)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, night := range nights {
+		isFree := true
+		for _, booking := range bookings {
+			if booking.ID != excludeBookingID && booking.Overlaps(night, night.AddDate(0, 0, 1)) {
+				isFree = false
+				break
+			}
+		}
+		if isFree {
+			free = append(free, night.Format(types.DateLayout))
+		} else {
+			booked = append(booked, night.Format(types.DateLayout))
+		}
+	}
+
+	return free, booked, nil
+}
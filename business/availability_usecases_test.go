@@ -0,0 +1,58 @@
+package business
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestCheckAvailability(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	hotelID, roomIDs := addTestHotel(t, m, "101", "102")
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	night := func(n int) string { return from.AddDate(0, 0, n).Format(types.DateLayout) }
+
+	// Room 101 is held on the second night, and was held on the third
+	if _, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from.AddDate(0, 0, 1), TillDate: from.AddDate(0, 0, 2), Adults: 1}); err != nil {
+		t.Fatal(err)
+	}
+	canceledID, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from.AddDate(0, 0, 2), TillDate: from.AddDate(0, 0, 3), Adults: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CancelBooking(ctx, canceledID); err != nil {
+		t.Fatal(err)
+	}
+
+	availability, err := m.CheckAvailability(ctx, hotelID, from, from.AddDate(0, 0, 3), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		roomIDs[0]: {night(0), night(2)},
+		roomIDs[1]: {night(0), night(1), night(2)},
+	}
+	if len(availability) != len(want) {
+		t.Fatalf("got %d rooms, want %d", len(availability), len(want))
+	}
+	for _, room := range availability {
+		wantNights := want[room.RoomID]
+		if len(room.FreeNights) != len(wantNights) {
+			t.Fatalf("room %s: got free nights %v, want %v", room.Number, room.FreeNights, wantNights)
+		}
+		for i := range wantNights {
+			if room.FreeNights[i] != wantNights[i] {
+				t.Fatalf("room %s: got free nights %v, want %v", room.Number, room.FreeNights, wantNights)
+			}
+		}
+		if room.Available != (len(wantNights) == 3) {
+			t.Errorf("room %s: got available %v with free nights %v", room.Number, room.Available, room.FreeNights)
+		}
+	}
+}
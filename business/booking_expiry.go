@@ -0,0 +1,63 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// ExpirePendingBookings moves every Pending booking created more than holdTTL
+// ago to Expired, which releases its nights, and cancels the reservations left
+// with no line to stay in. It returns how many expired.
+func (m *Manager) ExpirePendingBookings(ctx context.Context, holdTTL time.Duration) (int, error) {
+	stale, err := m.BookingStore.GetBookingsByStatusCreatedBefore(ctx, types.StatusPending, time.Now().Add(-holdTTL))
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int
+	reservationIDs := map[string]bool{}
+	for _, booking := range stale {
+		_, err := m.transitionBooking(ctx, booking.ID, types.StatusExpired)
+		if errors.Is(err, ErrInvalidTransition) {
+			// Confirmed or canceled since it was listed
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+		if booking.ReservationID != "" {
+			reservationIDs[booking.ReservationID] = true
+		}
+	}
+
+	for reservationID := range reservationIDs {
+		if err := m.syncReservation(ctx, reservationID); err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// RunBookingExpiry calls ExpirePendingBookings every interval until ctx is
+// done. Errors are logged and retried on the next tick.
+func (m *Manager) RunBookingExpiry(ctx context.Context, holdTTL, interval time.Duration, errorLogger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.ExpirePendingBookings(ctx, holdTTL); err != nil && ctx.Err() == nil {
+				errorLogger.Printf("Error expiring pending bookings: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package business
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestExpirePendingBookings(t *testing.T) {
+	const holdTTL = time.Hour
+
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	_, roomIDs := addTestHotel(t, m, "101", "102", "103")
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	book := func(roomID string, age time.Duration) *types.Booking {
+		t.Helper()
+		bookingID, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomID, FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		booking, err := m.BookingStore.GetBookingByID(ctx, bookingID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		booking.CreatedAt = booking.CreatedAt.Add(-age)
+		if err := m.BookingStore.UpdateBooking(ctx, booking); err != nil {
+			t.Fatal(err)
+		}
+		return booking
+	}
+
+	stale := book(roomIDs[0], 2*holdTTL)
+	confirmed := book(roomIDs[1], 2*holdTTL)
+	if _, err := m.ConfirmBooking(ctx, confirmed.ID); err != nil {
+		t.Fatal(err)
+	}
+	fresh := book(roomIDs[2], 0)
+
+	expired, err := m.ExpirePendingBookings(ctx, holdTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Fatalf("got %d expired, want 1", expired)
+	}
+
+	want := map[string]types.BookingStatus{
+		stale.ID:     types.StatusExpired,
+		confirmed.ID: types.StatusConfirmed,
+		fresh.ID:     types.StatusPending,
+	}
+	for id, status := range want {
+		booking, err := m.BookingStore.GetBookingByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if booking.BookingStatus != status {
+			t.Errorf("booking %s: got %s, want %s", id, booking.BookingStatus, status)
+		}
+	}
+
+	// The expired hold no longer keeps its nights
+	if _, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1}); err != nil {
+		t.Fatalf("booking the expired hold's nights: %v", err)
+	}
+}
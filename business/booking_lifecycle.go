@@ -0,0 +1,85 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var ErrInvalidTransition = errors.New("invalid booking status transition")
+
+// bookingTransitions is the booking lifecycle:
+//
+//	Pending -> Confirmed -> CheckedIn -> CheckedOut
+//	Pending, Confirmed -> Canceled
+//	Pending -> Expired
+//	Confirmed -> NoShow
+var bookingTransitions = map[types.BookingStatus][]types.BookingStatus{
+	types.StatusPending:   {types.StatusConfirmed, types.StatusCanceled, types.StatusExpired},
+	types.StatusConfirmed: {types.StatusCheckedIn, types.StatusNoShow, types.StatusCanceled},
+	types.StatusCheckedIn: {types.StatusCheckedOut},
+}
+
+func canTransition(from, to types.BookingStatus) bool {
+	for _, next := range bookingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) ConfirmBooking(ctx context.Context, bookingID string) (*types.Booking, error) {
+	return m.transitionBooking(ctx, bookingID, types.StatusConfirmed)
+}
+
+func (m *Manager) CheckInBooking(ctx context.Context, bookingID string) (*types.Booking, error) {
+	return m.transitionBooking(ctx, bookingID, types.StatusCheckedIn)
+}
+
+func (m *Manager) CheckOutBooking(ctx context.Context, bookingID string) (*types.Booking, error) {
+	return m.transitionBooking(ctx, bookingID, types.StatusCheckedOut)
+}
+
+func (m *Manager) MarkBookingNoShow(ctx context.Context, bookingID string) (*types.Booking, error) {
+	return m.transitionBooking(ctx, bookingID, types.StatusNoShow)
+}
+
+// transitionBooking moves a booking to the given status if the lifecycle allows
+// it, otherwise it returns an error wrapping ErrInvalidTransition.
+func (m *Manager) transitionBooking(ctx context.Context, bookingID string, to types.BookingStatus) (*types.Booking, error) {
+	booking, err := m.BookingStore.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := checkTransition(booking, to, now); err != nil {
+		return nil, err
+	}
+
+	booking.SetStatus(to, now)
+
+	if err := m.BookingStore.UpdateBooking(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+func checkTransition(booking *types.Booking, to types.BookingStatus, now time.Time) error {
+	from := booking.BookingStatus
+	if !canTransition(from, to) {
+		return fmt.Errorf("%w: booking is %s and cannot become %s", ErrInvalidTransition, from, to)
+	}
+
+	// A guest can neither arrive nor miss their arrival before the stay starts
+	if (to == types.StatusCheckedIn || to == types.StatusNoShow) && now.Before(types.StartOfDay(booking.FromDate)) {
+		return fmt.Errorf("%w: booking cannot become %s before its arrival date", ErrInvalidTransition, to)
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package business
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestCheckTransition(t *testing.T) {
+	statuses := []types.BookingStatus{
+		types.StatusPending,
+		types.StatusConfirmed,
+		types.StatusCheckedIn,
+		types.StatusCheckedOut,
+		types.StatusNoShow,
+		types.StatusCanceled,
+		types.StatusExpired,
+	}
+	allowed := map[[2]types.BookingStatus]bool{
+		{types.StatusPending, types.StatusConfirmed}:    true,
+		{types.StatusPending, types.StatusCanceled}:     true,
+		{types.StatusPending, types.StatusExpired}:      true,
+		{types.StatusConfirmed, types.StatusCheckedIn}:  true,
+		{types.StatusConfirmed, types.StatusNoShow}:     true,
+		{types.StatusConfirmed, types.StatusCanceled}:   true,
+		{types.StatusCheckedIn, types.StatusCheckedOut}: true,
+	}
+
+	now := time.Now()
+	for _, from := range statuses {
+		for _, to := range statuses {
+			booking := &types.Booking{BookingStatus: from, FromDate: now.AddDate(0, 0, -1), TillDate: now.AddDate(0, 0, 1)}
+			err := checkTransition(booking, to, now)
+			switch {
+			case allowed[[2]types.BookingStatus{from, to}] && err != nil:
+				t.Errorf("%s -> %s: got %v, want it allowed", from, to, err)
+			case !allowed[[2]types.BookingStatus{from, to}] && !errors.Is(err, ErrInvalidTransition):
+				t.Errorf("%s -> %s: got %v, want %v", from, to, err, ErrInvalidTransition)
+			}
+		}
+	}
+}
+
+func TestCheckTransitionBeforeArrival(t *testing.T) {
+	now := time.Now()
+	booking := &types.Booking{BookingStatus: types.StatusConfirmed, FromDate: now.AddDate(0, 0, 2), TillDate: now.AddDate(0, 0, 4)}
+
+	for _, to := range []types.BookingStatus{types.StatusCheckedIn, types.StatusNoShow} {
+		if err := checkTransition(booking, to, now); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s before arrival: got %v, want %v", to, err, ErrInvalidTransition)
+		}
+	}
+	if err := checkTransition(booking, types.StatusCanceled, now); err != nil {
+		t.Errorf("Canceled before arrival: got %v, want it allowed", err)
+	}
+}
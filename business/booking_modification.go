@@ -0,0 +1,117 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/pricing"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var ErrBookingNotModifiable = errors.New("booking cannot be modified")
+
+// ErrBookingInReservation is returned for a booking that is a line of a
+// reservation, whose lines share their dates.
+var ErrBookingInReservation = errors.New("booking is part of a reservation")
+
+// ModifyBooking moves a Pending or Confirmed booking to another room and/or
+// other dates. The new stay is checked against every other booking, re-priced,
+// and stored in a single update that also moves the held nights, so the
+// booking either keeps its old stay or gets the new one. Lines of a
+// reservation cannot be modified on their own.
+func (m *Manager) ModifyBooking(ctx context.Context, bookingID string, params types.ModifyBookingParams) (*types.Booking, error) {
+	booking, err := m.BookingStore.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.ReservationID != "" {
+		return nil, fmt.Errorf("%w %s", ErrBookingInReservation, booking.ReservationID)
+	}
+
+	if booking.BookingStatus != types.StatusPending && booking.BookingStatus != types.StatusConfirmed {
+		return nil, fmt.Errorf("%w: booking is %s", ErrBookingNotModifiable, booking.BookingStatus)
+	}
+
+	modification := types.BookingModification{
+		ModifiedAt:     time.Now(),
+		PrevRoomID:     booking.RoomID,
+		PrevFromDate:   booking.FromDate,
+		PrevTillDate:   booking.TillDate,
+		PrevTotalPrice: booking.TotalPrice,
+		RoomID:         booking.RoomID,
+		FromDate:       booking.FromDate,
+		TillDate:       booking.TillDate,
+	}
+	if params.RoomID != "" {
+		modification.RoomID = params.RoomID
+	}
+	if !params.FromDate.IsZero() {
+		modification.FromDate = params.FromDate
+	}
+	if !params.TillDate.IsZero() {
+		modification.TillDate = params.TillDate
+	}
+
+	if len(types.Nights(modification.FromDate, modification.TillDate)) == 0 {
+		return nil, fmt.Errorf("%w: booking must cover at least one night", types.ErrInvalidStay)
+	}
+
+	room, err := m.RoomStore.GetRoomByID(ctx, modification.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if room.Closing {
+		return nil, fmt.Errorf("%w: room %s is being deleted", types.ErrRoomUnavailable, room.Number)
+	}
+
+	if booking.Guests() > room.Capacity() {
+		return nil, fmt.Errorf("%w: room %s sleeps %d", types.ErrOverCapacity, room.Number, room.Capacity())
+	}
+
+	if err := m.checkRoomIsFree(ctx, room.ID, modification.FromDate, modification.TillDate, booking.ID); err != nil {
+		return nil, err
+	}
+
+	quote, err := pricing.QuoteStay(room, modification.FromDate, modification.TillDate)
+	if err != nil {
+		return nil, err
+	}
+	modification.TotalPrice = quote.Total
+
+	prev := *booking
+	booking.RoomID = modification.RoomID
+	booking.FromDate = modification.FromDate
+	booking.TillDate = modification.TillDate
+	booking.TotalPrice = quote.Total
+	booking.Currency = quote.Currency
+	booking.Nights = quote.BookedNights()
+	booking.Modifications = append(booking.Modifications, modification)
+
+	err = m.BookingStore.UpdateBooking(ctx, booking)
+	if errors.Is(err, types.ErrRoomUnavailable) {
+		// Another request took some of the nights since the check above
+		if conflictErr := m.checkRoomIsFree(ctx, room.ID, booking.FromDate, booking.TillDate, booking.ID); conflictErr != nil {
+			return nil, conflictErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Move back if the new room started closing before the booking moved in
+	if room.ID != prev.RoomID {
+		if err := m.checkRoomOpen(ctx, room.ID); err != nil {
+			if undoErr := m.BookingStore.UpdateBooking(ctx, &prev); undoErr != nil {
+				log.Printf("Error moving booking %s back from closing room %s: %v\n", booking.ID, room.ID, undoErr)
+			}
+			return nil, err
+		}
+	}
+
+	return booking, nil
+}
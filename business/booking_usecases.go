@@ -3,7 +3,11 @@ package business
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"time"
 
+	"github.com/mkabdelrahman/hotel-reservation/pricing"
 	"github.com/mkabdelrahman/hotel-reservation/types"
 )
 
@@ -17,52 +21,88 @@ func (m *Manager) AddNewBooking(ctx context.Context, params types.NewBookingPara
 		return "", errors.New("user not found")
 	}
 
+	booking, err := m.bookRoom(ctx, params, "")
+	if err != nil {
+		return "", err
+	}
+
+	return booking.ID, nil
+}
+
+// bookRoom prices and inserts a booking whose user has been checked already.
+func (m *Manager) bookRoom(ctx context.Context, params types.NewBookingParams, reservationID string) (*types.Booking, error) {
 	// Make sure room ID in params exists
 	room, err := m.RoomStore.GetRoomByID(ctx, params.RoomID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if room == nil {
-		return "", errors.New("room not found")
+		return nil, errors.New("room not found")
+	}
+	if room.Closing {
+		return nil, fmt.Errorf("%w: room %s is being deleted", types.ErrRoomUnavailable, room.Number)
+	}
+
+	// Make sure the room sleeps everyone
+	if params.Guests() > room.Capacity() {
+		return nil, fmt.Errorf("%w: room %s sleeps %d", types.ErrOverCapacity, room.Number, room.Capacity())
 	}
 
 	// Check if the room is already booked for the specified time range
-	existingBooking, err := m.BookingStore.GetBookingByRoomAndTimeRange(ctx, params.RoomID, params.FromDate, params.TillDate)
-	if err != nil {
-		return "", err
+	if err := m.checkRoomIsFree(ctx, params.RoomID, params.FromDate, params.TillDate, ""); err != nil {
+		return nil, err
 	}
-	if existingBooking != nil {
-		// Check if the existing booking has been canceled
-		if existingBooking.BookingStatus == types.StatusCanceled {
-			// Proceed with the new booking
-		} else {
-			return "", errors.New("room is already booked for the specified time range")
-		}
+
+	// Price the stay
+	quote, err := pricing.QuoteStay(room, params.FromDate, params.TillDate)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create a new booking from params
+	// Create a new booking from params, freezing the quoted price
 	newBooking := types.NewBookingFromParams(params)
-
-	// Insert the new booking, the db will return a booking with the id field filled
+	newBooking.ReservationID = reservationID
+	newBooking.TotalPrice = quote.Total
+	newBooking.Currency = quote.Currency
+	newBooking.Nights = quote.BookedNights()
+
+	// Insert the new booking, the db will return a booking with the id field filled.
+	// The store holds the room's nights atomically, so of two concurrent requests
+	// that both passed the check above only one succeeds.
 	insertedBooking, err := m.BookingStore.InsertBooking(ctx, newBooking)
+	if errors.Is(err, types.ErrRoomUnavailable) {
+		// Another request won the race, report the nights it took
+		if conflictErr := m.checkRoomIsFree(ctx, params.RoomID, params.FromDate, params.TillDate, ""); conflictErr != nil {
+			return nil, conflictErr
+		}
+	}
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Change the occupied field of the room to true and update
-	room.Occupied = true
-	err = m.RoomStore.UpdateRoom(ctx, room)
-	if err != nil {
-		// Rollback the booking if updating the room fails
-		rollbackErr := m.BookingStore.DeleteBookingByID(ctx, insertedBooking.ID)
-		if rollbackErr != nil {
-			return "", err
+	// Undo the booking if the room started closing before it was stored
+	if err := m.checkRoomOpen(ctx, room.ID); err != nil {
+		if delErr := m.BookingStore.DeleteBookingByID(ctx, insertedBooking.ID); delErr != nil {
+			log.Printf("Error undoing booking %s of closing room %s: %v\n", insertedBooking.ID, room.ID, delErr)
 		}
-
-		return "", err
+		return nil, err
 	}
 
-	return insertedBooking.ID, nil
+	return insertedBooking, nil
+}
+
+// checkRoomIsFree returns a *types.BookingConflictError listing the nights of
+// [from, till) on which the room is held by an active booking other than
+// excludeBookingID.
+func (m *Manager) checkRoomIsFree(ctx context.Context, roomID string, from, till time.Time, excludeBookingID string) error {
+	booked, err := m.bookedNights(ctx, roomID, types.Nights(from, till), excludeBookingID)
+	if err != nil {
+		return err
+	}
+	if len(booked) > 0 {
+		return &types.BookingConflictError{RoomID: roomID, Nights: booked}
+	}
+	return nil
 }
 
 func (m *Manager) ListBookings(ctx context.Context) ([]*types.Booking, error) {
@@ -83,26 +123,71 @@ func (m *Manager) ListUserBookings(ctx context.Context, userID string) ([]*types
 	return bookings, nil
 }
 
-func (m *Manager) CancelBooking(ctx context.Context, bookingID string) error {
+// CancelBooking cancels the booking and records the refund and penalty due
+// under the hotel's cancellation policy. Lines of a reservation go through
+// the reservation so its status follows.
+func (m *Manager) CancelBooking(ctx context.Context, bookingID string) (*types.Booking, error) {
 	booking, err := m.BookingStore.GetBookingByID(ctx, bookingID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if booking.ReservationID != "" {
+		_, lines, err := m.CancelReservationLine(ctx, booking.ReservationID, booking.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range lines {
+			if line.ID == booking.ID {
+				return line, nil
+			}
+		}
+		return nil, ErrBookingNotInReservation
+	}
+
+	return m.cancelBooking(ctx, booking)
+}
+
+func (m *Manager) cancelBooking(ctx context.Context, booking *types.Booking) (*types.Booking, error) {
+	now := time.Now()
+	if err := checkTransition(booking, types.StatusCanceled, now); err != nil {
+		return nil, err
 	}
 
-	if booking == nil {
-		return errors.New("booking not found")
+	policy, err := m.cancellationPolicy(ctx, booking.RoomID)
+	if err != nil {
+		return nil, err
 	}
 
-	if booking.BookingStatus == types.StatusCanceled {
-		return errors.New("booking is already canceled")
+	cancellation := pricing.CancellationCharge(policy, booking, now)
+	booking.Cancellation = &cancellation
+	booking.SetStatus(types.StatusCanceled, now)
+
+	if err := m.BookingStore.UpdateBooking(ctx, booking); err != nil {
+		return nil, err
 	}
 
-	booking.BookingStatus = types.StatusCanceled
+	return booking, nil
+}
+
+// cancellationPolicy returns the policy of the hotel the room belongs to.
+// Bookings of rooms or hotels that no longer exist are canceled for free.
+func (m *Manager) cancellationPolicy(ctx context.Context, roomID string) (types.CancellationPolicy, error) {
+	room, err := m.RoomStore.GetRoomByID(ctx, roomID)
+	if errors.Is(err, types.ErrNotFound) {
+		return types.CancellationPolicy{}, nil
+	}
+	if err != nil {
+		return types.CancellationPolicy{}, err
+	}
 
-	err = m.BookingStore.UpdateBooking(ctx, booking)
+	hotel, err := m.HotelStore.GetHotel(ctx, room.HotelID)
+	if errors.Is(err, types.ErrNotFound) {
+		return types.CancellationPolicy{}, nil
+	}
 	if err != nil {
-		return err
+		return types.CancellationPolicy{}, err
 	}
 
-	return nil
+	return hotel.CancellationPolicy, nil
 }
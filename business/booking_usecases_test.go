@@ -0,0 +1,175 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestAddNewBookingConcurrent(t *testing.T) {
+	testAddNewBookingConcurrent(t, newTestManager(t))
+}
+
+// TestAddNewBookingConcurrentMongo runs against the Mongo stores, where only
+// the unique slot _id stops a double booking.
+func TestAddNewBookingConcurrentMongo(t *testing.T) {
+	testAddNewBookingConcurrent(t, newMongoTestManager(t))
+}
+
+func testAddNewBookingConcurrent(t *testing.T, m *Manager) {
+	const attempts = 20
+
+	ctx := context.Background()
+
+	userID, err := m.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotelID, err := m.AddNewHotel(ctx, types.NewHotelParams{Name: "Dolcica", Location: "Madrid", Rating: types.Excellent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomID, err := m.AddNewRoom(ctx, types.NewRoomParams{Number: "101", Type: types.StandardRoom, Price: 100}, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	params := types.NewBookingParams{
+		UserID:   userID,
+		RoomID:   roomID,
+		FromDate: from,
+		TillDate: from.AddDate(0, 0, 3),
+		Adults:   2,
+	}
+
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+		errs  = make(chan error, attempts)
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := m.AddNewBooking(ctx, params)
+			errs <- err
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	var won int
+	for err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, types.ErrRoomUnavailable):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("got %d successful bookings, want exactly 1", won)
+	}
+
+	bookings, err := m.ListBookings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookings) != 1 {
+		t.Fatalf("got %d stored bookings, want 1", len(bookings))
+	}
+}
+
+func TestModifyBookingRefusesReservationLine(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	reservation := addTestReservation(t, m, 2)
+
+	_, err := m.ModifyBooking(ctx, reservation.BookingIDs[0], types.ModifyBookingParams{TillDate: reservation.TillDate.AddDate(0, 0, 1)})
+	if !errors.Is(err, ErrBookingInReservation) {
+		t.Fatalf("got %v, want %v", err, ErrBookingInReservation)
+	}
+}
+
+func TestAddNewBookingConflictIgnoresCanceled(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	_, roomIDs := addTestHotel(t, m, "101")
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	stay := func(first, last int) types.NewBookingParams {
+		return types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from.AddDate(0, 0, first), TillDate: from.AddDate(0, 0, last), Adults: 1}
+	}
+
+	// A canceled booking overlaps first, then an active one
+	canceledID, err := m.AddNewBooking(ctx, stay(0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CancelBooking(ctx, canceledID); err != nil {
+		t.Fatal(err)
+	}
+	activeID, err := m.AddNewBooking(ctx, stay(1, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlapping, err := m.BookingStore.GetActiveBookingsByRoomAndTimeRange(ctx, roomIDs[0], from, from.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overlapping) != 1 || overlapping[0].ID != activeID {
+		t.Fatalf("got %d overlapping bookings, want only the active one", len(overlapping))
+	}
+
+	_, err = m.AddNewBooking(ctx, stay(0, 4))
+	var conflict *types.BookingConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got %v, want a %T", err, conflict)
+	}
+	want := []string{from.AddDate(0, 0, 1).Format(types.DateLayout), from.AddDate(0, 0, 2).Format(types.DateLayout)}
+	if len(conflict.Nights) != len(want) || conflict.Nights[0] != want[0] || conflict.Nights[1] != want[1] {
+		t.Fatalf("got conflicting nights %v, want %v", conflict.Nights, want)
+	}
+}
+
+func TestAddNewBookingChecksCapacity(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	hotelID, roomIDs := addTestHotel(t, m, "101")
+	// A standard room with a sofa bed
+	largeID, err := m.AddNewRoom(ctx, types.NewRoomParams{Number: "102", Type: types.StandardRoom, Price: 100, MaxOccupancy: 3}, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	family := func(roomID string) types.NewBookingParams {
+		return types.NewBookingParams{UserID: userID, RoomID: roomID, FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 2, Children: 1}
+	}
+
+	if _, err := m.AddNewBooking(ctx, family(roomIDs[0])); !errors.Is(err, types.ErrOverCapacity) {
+		t.Fatalf("standard room: got %v, want %v", err, types.ErrOverCapacity)
+	}
+
+	availability, err := m.CheckAvailability(ctx, hotelID, from, from.AddDate(0, 0, 2), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(availability) != 1 || availability[0].RoomID != largeID {
+		t.Fatalf("got %d rooms for three guests, want only room 102", len(availability))
+	}
+
+	if _, err := m.AddNewBooking(ctx, family(largeID)); err != nil {
+		t.Fatalf("room with a max occupancy of 3: %v", err)
+	}
+}
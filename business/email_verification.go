@@ -0,0 +1,110 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// EmailVerificationTTL bounds how long a verification link works; a new one
+// can be asked for with ResendVerification.
+const EmailVerificationTTL = time.Hour * 24
+
+const emailVerificationPurpose = "email_verification"
+
+// RegisterUser creates an account that cannot log in until the user follows
+// the verification link sent to their email.
+func (m *Manager) RegisterUser(ctx context.Context, params types.NewUserParams) (string, error) {
+	user, err := types.NewUserFromParams(params)
+	if err != nil {
+		return "", err
+	}
+	user.Status = types.AccountPendingVerification
+
+	insertedUser, err := m.UserStore.InsertUser(ctx, user)
+	if err != nil {
+		return "", err
+	}
+
+	if err := m.queueVerification(ctx, insertedUser); err != nil {
+		return "", err
+	}
+
+	return insertedUser.ID.Hex(), nil
+}
+
+// ResendVerification sends a new verification link to the email. Unknown and
+// verified emails are not an error, so the caller cannot learn which emails
+// are registered.
+func (m *Manager) ResendVerification(ctx context.Context, params types.ResendVerificationParams) error {
+	user, err := m.UserStore.GetUserByEmail(ctx, params.Email)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EffectiveStatus() != types.AccountPendingVerification {
+		return nil
+	}
+	return m.queueVerification(ctx, user)
+}
+
+// VerifyEmail activates the account a verification link was sent for.
+// Following a link again once the account is active does nothing.
+func (m *Manager) VerifyEmail(ctx context.Context, token string) (*types.User, error) {
+	userID, err := m.Tokens.ParseLinkToken(emailVerificationPurpose, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", types.ErrInvalidUserToken, err)
+	}
+
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch user.EffectiveStatus() {
+	case types.AccountActive:
+		return user, nil
+	case types.AccountPendingVerification:
+	default:
+		return nil, user.EffectiveStatus().CanLogIn()
+	}
+
+	change := types.AccountStatusChange{
+		Reason:    "email verified",
+		ChangedBy: userID,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := m.UserStore.UpdateUserStatus(ctx, userID, types.AccountActive, change); err != nil {
+		return nil, err
+	}
+
+	return m.UserStore.GetUserByID(ctx, userID)
+}
+
+func (m *Manager) queueVerification(ctx context.Context, user *types.User) error {
+	token, err := m.Tokens.SignLinkToken(emailVerificationPurpose, user.ID.Hex(), EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	link := m.PublicURL + "/api/auth/verify?token=" + url.QueryEscape(token)
+
+	notification := &types.Notification{
+		Kind:    types.NotificationEmailVerification,
+		UserID:  user.ID.Hex(),
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hello %s,\n\nOpen this link to verify your email and activate your account:\n\n%s\n\nIt expires in %s.",
+			user.FirstName, link, EmailVerificationTTL),
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(EmailVerificationTTL),
+	}
+	_, err = m.OutboxStore.InsertNotification(ctx, notification)
+	return err
+}
@@ -2,7 +2,12 @@ package business
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
 
+	"github.com/mkabdelrahman/hotel-reservation/pricing"
 	"github.com/mkabdelrahman/hotel-reservation/types"
 )
 
@@ -17,26 +22,68 @@ func (m *Manager) AddNewHotel(ctx context.Context, params types.NewHotelParams)
 	return insertedHotel.ID, nil
 }
 
+// GetHotel returns the hotel with the IDs of the rooms that belong to it.
+func (m *Manager) GetHotel(ctx context.Context, hotelID string) (*types.Hotel, error) {
+	hotel, err := m.HotelStore.GetHotel(ctx, hotelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.fillHotelRooms(ctx, hotel); err != nil {
+		return nil, err
+	}
+	return hotel, nil
+}
+
 func (m *Manager) ListHotels(ctx context.Context) ([]*types.Hotel, error) {
 
 	hotels, err := m.HotelStore.GetHotels(ctx)
 	if err != nil {
 		return nil, err
 	}
+	for _, hotel := range hotels {
+		if err := m.fillHotelRooms(ctx, hotel); err != nil {
+			return nil, err
+		}
+	}
 	return hotels, nil
 }
 
-func (m *Manager) QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.Hotel, error) {
+// fillHotelRooms sets hotel.Rooms from the rooms whose HotelID is the hotel,
+// which is the only stored link between the two.
+func (m *Manager) fillHotelRooms(ctx context.Context, hotel *types.Hotel) error {
+	rooms, err := m.RoomStore.GetRoomsByHotelID(ctx, hotel.ID)
+	if err != nil {
+		return err
+	}
+
+	hotel.Rooms = make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		hotel.Rooms = append(hotel.Rooms, room.ID)
+	}
+	return nil
+}
+
+func (m *Manager) QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.HotelSearchResult, error) {
 
-	hotels, err := m.HotelStore.QueryHotels(ctx, criteria)
+	results, err := m.HotelStore.QueryHotels(ctx, criteria)
 	if err != nil {
 		return nil, err
 	}
-	return hotels, nil
+	for _, result := range results {
+		if err := m.fillHotelRooms(ctx, result.Hotel); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
 }
 
 func (m *Manager) AddNewRoom(ctx context.Context, params types.NewRoomParams, hotelID string) (string, error) {
 
+	// Make sure the hotel exists before adding a room to it
+	if _, err := m.HotelStore.GetHotel(ctx, hotelID); err != nil {
+		return "", err
+	}
+
 	room := &types.Room{
 		HotelID:     hotelID,
 		Number:      params.Number,
@@ -44,25 +91,191 @@ func (m *Manager) AddNewRoom(ctx context.Context, params types.NewRoomParams, ho
 		Type:        params.Type,
 		Description: params.Description,
 		Price:       params.Price,
-		Occupied:    params.Occupied,
+		Rates:       params.Rates,
+
+		MaxOccupancy: params.MaxOccupancy,
 	}
 	insertedRoom, err := m.RoomStore.InsertRoom(ctx, room)
 	if err != nil {
 		return "", err
 	}
+	return insertedRoom.ID, nil
+}
+
+// ErrHasFutureBookings is returned when deleting a room or hotel that guests
+// are still booked into.
+var ErrHasFutureBookings = errors.New("room has future bookings")
 
+// ReplaceHotel overwrites every field of the hotel but its ID and rooms.
+func (m *Manager) ReplaceHotel(ctx context.Context, hotelID string, params types.NewHotelParams) (*types.Hotel, error) {
 	hotel, err := m.HotelStore.GetHotel(ctx, hotelID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	hotel.Name = params.Name
+	hotel.Location = params.Location
+	hotel.Rating = params.Rating
+	hotel.CancellationPolicy = params.CancellationPolicy
+
+	if err := m.HotelStore.UpdateHotel(ctx, hotel); err != nil {
+		return nil, err
 	}
-	hotel.Rooms = append(hotel.Rooms, insertedRoom.ID)
-	err = m.HotelStore.UpdateHotel(ctx, hotel)
+	return hotel, nil
+}
+
+func (m *Manager) UpdateHotel(ctx context.Context, hotelID string, params types.UpdateHotelParams) (*types.Hotel, error) {
+	hotel, err := m.HotelStore.GetHotel(ctx, hotelID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return room.ID, nil
+	return m.ReplaceHotel(ctx, hotelID, params.Apply(hotel))
+}
+
+// DeleteHotel deletes the hotel and its rooms, unless one of the rooms has
+// future bookings.
+func (m *Manager) DeleteHotel(ctx context.Context, hotelID string) error {
+	if _, err := m.HotelStore.GetHotel(ctx, hotelID); err != nil {
+		return err
+	}
+
+	rooms, err := m.RoomStore.GetRoomsByHotelID(ctx, hotelID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.closeRooms(ctx, rooms); err != nil {
+		return err
+	}
+
+	for _, room := range rooms {
+		if err := m.RoomStore.DeleteRoom(ctx, room.ID); err != nil {
+			return err
+		}
+	}
+	return m.HotelStore.DeleteHotel(ctx, hotelID)
+}
+
+// ReplaceRoom overwrites every field of the room but its ID and hotel.
+func (m *Manager) ReplaceRoom(ctx context.Context, roomID string, params types.NewRoomParams) (*types.Room, error) {
+	room, err := m.RoomStore.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	room.Number = params.Number
+	room.Floor = params.Floor
+	room.Type = params.Type
+	room.Description = params.Description
+	room.Price = params.Price
+	room.Rates = params.Rates
+	room.MaxOccupancy = params.MaxOccupancy
+
+	if err := m.RoomStore.UpdateRoom(ctx, room); err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+func (m *Manager) UpdateRoom(ctx context.Context, roomID string, params types.UpdateRoomParams) (*types.Room, error) {
+	room, err := m.RoomStore.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return m.ReplaceRoom(ctx, roomID, params.Apply(room))
+}
+
+// DeleteRoom deletes the room, unless it has future bookings.
+func (m *Manager) DeleteRoom(ctx context.Context, roomID string) error {
+	room, err := m.RoomStore.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	if err := m.closeRooms(ctx, []types.Room{*room}); err != nil {
+		return err
+	}
+
+	return m.RoomStore.DeleteRoom(ctx, roomID)
+}
+
+// closeRooms closes the rooms to new bookings, then checks that none has
+// future bookings, reopening them if one does. A booking made while a room
+// closes either is seen here or sees the room closing and is undone, so no
+// booking is left on a deleted room.
+func (m *Manager) closeRooms(ctx context.Context, rooms []types.Room) error {
+	for i, room := range rooms {
+		if err := m.RoomStore.SetRoomClosing(ctx, room.ID, true); err != nil {
+			m.reopenRooms(ctx, rooms[:i])
+			return err
+		}
+	}
+
+	for _, room := range rooms {
+		if err := m.checkNoFutureBookings(ctx, &room); err != nil {
+			m.reopenRooms(ctx, rooms)
+			return err
+		}
+	}
+	return nil
+}
+
+// reopenRooms undoes closeRooms. Failures are only logged since the caller
+// already returns the original error.
+func (m *Manager) reopenRooms(ctx context.Context, rooms []types.Room) {
+	for _, room := range rooms {
+		if err := m.RoomStore.SetRoomClosing(ctx, room.ID, false); err != nil {
+			log.Printf("Error reopening room %s: %v\n", room.ID, err)
+		}
+	}
+}
+
+// checkRoomOpen returns types.ErrRoomUnavailable if the room is closing or
+// gone, as when it is being deleted.
+func (m *Manager) checkRoomOpen(ctx context.Context, roomID string) error {
+	room, err := m.RoomStore.GetRoomByID(ctx, roomID)
+	if errors.Is(err, types.ErrNotFound) {
+		return fmt.Errorf("%w: room %s was deleted", types.ErrRoomUnavailable, roomID)
+	}
+	if err != nil {
+		return err
+	}
+	if room.Closing {
+		return fmt.Errorf("%w: room %s is being deleted", types.ErrRoomUnavailable, room.Number)
+	}
+	return nil
+}
+
+// checkNoFutureBookings returns ErrHasFutureBookings if an active booking of
+// the room ends after today.
+func (m *Manager) checkNoFutureBookings(ctx context.Context, room *types.Room) error {
+	bookings, err := m.BookingStore.GetActiveBookingsByRoomID(ctx, room.ID)
+	if err != nil {
+		return err
+	}
+
+	today := types.StartOfDay(time.Now())
+	for _, booking := range bookings {
+		if types.StartOfDay(booking.TillDate).After(today) {
+			return fmt.Errorf("%w: room %s is booked until %s", ErrHasFutureBookings, room.Number, booking.TillDate.Format(types.DateLayout))
+		}
+	}
+	return nil
 }
 
 func (m *Manager) ListRoomsForHotel(ctx context.Context, hotelID string) ([]types.Room, error) {
+	// Make sure the hotel exists
+	if _, err := m.HotelStore.GetHotel(ctx, hotelID); err != nil {
+		return nil, err
+	}
 	return m.RoomStore.GetRoomsByHotelID(ctx, hotelID)
 }
+
+func (m *Manager) QuoteStay(ctx context.Context, roomID string, from, till time.Time) (*pricing.Quote, error) {
+	room, err := m.RoomStore.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pricing.QuoteStay(room, from, till)
+}
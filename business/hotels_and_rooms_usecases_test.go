@@ -0,0 +1,205 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestQueryHotelsPricesStay(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	hotelID, err := m.AddNewHotel(ctx, types.NewHotelParams{Name: "Dolcica", Location: "Madrid", Rating: types.Excellent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.AddNewRoom(ctx, types.NewRoomParams{Number: "101", Type: types.StandardRoom, Price: 100, Rates: types.RatePlan{WeekendPrice: 200}}, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A Friday and Saturday night, both at the weekend rate
+	friday := time.Now().UTC().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	for friday.Weekday() != time.Friday {
+		friday = friday.AddDate(0, 0, 1)
+	}
+	weekend := types.QueryCriteria{From: friday, Till: friday.AddDate(0, 0, 2)}
+
+	tests := []struct {
+		name      string
+		criteria  types.QueryCriteria
+		wantPrice float64
+		wantTotal float64
+	}{
+		{"without dates at the base price", types.QueryCriteria{MaxPrice: 150}, 100, 0},
+		{"with dates at the quoted price", weekend, 200, 400},
+		{"with dates filtered by the quoted price", types.QueryCriteria{From: weekend.From, Till: weekend.Till, MaxPrice: 150}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := m.QueryHotels(ctx, tt.criteria)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantPrice == 0 {
+				if len(results) != 0 {
+					t.Fatalf("got %d results, want none", len(results))
+				}
+				return
+			}
+			if len(results) != 1 {
+				t.Fatalf("got %d results, want 1", len(results))
+			}
+			if got := results[0]; got.CheapestPrice != tt.wantPrice || got.CheapestTotal != tt.wantTotal {
+				t.Fatalf("got price %v and total %v, want %v and %v", got.CheapestPrice, got.CheapestTotal, tt.wantPrice, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestDeleteRoomWithFutureBookings(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	_, roomIDs := addTestHotel(t, m, "101")
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	stay := types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1}
+	bookingID, err := m.AddNewBooking(ctx, stay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.DeleteRoom(ctx, roomIDs[0]); !errors.Is(err, ErrHasFutureBookings) {
+		t.Fatalf("got %v, want %v", err, ErrHasFutureBookings)
+	}
+
+	// The room takes bookings again once the delete is refused
+	later := stay
+	later.FromDate, later.TillDate = stay.TillDate, stay.TillDate.AddDate(0, 0, 1)
+	laterID, err := m.AddNewBooking(ctx, later)
+	if err != nil {
+		t.Fatalf("booking after a refused delete: %v", err)
+	}
+
+	for _, id := range []string{bookingID, laterID} {
+		if _, err := m.CancelBooking(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.DeleteRoom(ctx, roomIDs[0]); err != nil {
+		t.Fatalf("deleting a room with only canceled bookings: %v", err)
+	}
+}
+
+func TestAddNewBookingRefusesClosingRoom(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	_, roomIDs := addTestHotel(t, m, "101")
+
+	// As while DeleteRoom checks the room's bookings
+	if err := m.RoomStore.SetRoomClosing(ctx, roomIDs[0], true); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	_, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1})
+	if !errors.Is(err, types.ErrRoomUnavailable) {
+		t.Fatalf("got %v, want %v", err, types.ErrRoomUnavailable)
+	}
+}
+
+// closingBookingStore closes the room of each booking just after storing it,
+// as a DeleteRoom running at the same time would.
+type closingBookingStore struct {
+	db.BookingStore
+	rooms db.RoomStore
+}
+
+func (s closingBookingStore) InsertBooking(ctx context.Context, booking *types.Booking) (*types.Booking, error) {
+	inserted, err := s.BookingStore.InsertBooking(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+	return inserted, s.rooms.SetRoomClosing(ctx, booking.RoomID, true)
+}
+
+func TestAddNewBookingUndoneWhenRoomCloses(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+	_, roomIDs := addTestHotel(t, m, "101")
+	m.BookingStore = closingBookingStore{BookingStore: m.BookingStore, rooms: m.RoomStore}
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	_, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1})
+	if !errors.Is(err, types.ErrRoomUnavailable) {
+		t.Fatalf("got %v, want %v", err, types.ErrRoomUnavailable)
+	}
+
+	bookings, err := m.ListBookings(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookings) != 0 {
+		t.Fatalf("got %d stored bookings, want the booking undone", len(bookings))
+	}
+}
+
+func TestUpdateHotelKeepsUnsetFields(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	hotelID, _ := addTestHotel(t, m)
+
+	location := "Barcelona"
+	if _, err := m.UpdateHotel(ctx, hotelID, types.UpdateHotelParams{Location: &location}); err != nil {
+		t.Fatal(err)
+	}
+	hotel, err := m.GetHotel(ctx, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hotel.Name != "Dolcica" || hotel.Location != location || hotel.Rating != types.Excellent {
+		t.Fatalf("got %s in %s rated %v, want only the location changed", hotel.Name, hotel.Location, hotel.Rating)
+	}
+}
+
+func TestHotelRoomsFollowRooms(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	hotelID, roomIDs := addTestHotel(t, m, "101", "102")
+
+	hotelRooms := func() []string {
+		t.Helper()
+		hotel, err := m.GetHotel(ctx, hotelID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hotel.Rooms
+	}
+
+	if got := hotelRooms(); len(got) != 2 {
+		t.Fatalf("got rooms %v, want both rooms", got)
+	}
+
+	if err := m.DeleteRoom(ctx, roomIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := hotelRooms(); len(got) != 1 || got[0] != roomIDs[1] {
+		t.Fatalf("got rooms %v, want only %s", got, roomIDs[1])
+	}
+
+	// Deleting the hotel leaves no room behind
+	if err := m.DeleteHotel(ctx, hotelID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RoomStore.GetRoomByID(ctx, roomIDs[1]); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("room of the deleted hotel: got %v, want %v", err, types.ErrNotFound)
+	}
+}
@@ -0,0 +1,189 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// loginPolicy slows down password guessing. After FreeAttempts failures each
+// attempt waits BaseDelay, doubled per further failure up to MaxDelay, after
+// the last one. At LockoutAttempts failures logging in is locked for
+// LockoutDuration. Failures are forgotten on success, on unlock, when a
+// lockout ends, or after ResetAfter without one.
+type loginPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutAttempts int
+	LockoutDuration time.Duration
+	ResetAfter      time.Duration
+}
+
+var (
+	accountLoginPolicy = loginPolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAttempts: 10,
+		LockoutDuration: 30 * time.Minute,
+		ResetAfter:      24 * time.Hour,
+	}
+
+	// Many users may share an IP behind a NAT, so it gets more attempts
+	ipLoginPolicy = loginPolicy{
+		FreeAttempts:    20,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAttempts: 100,
+		LockoutDuration: time.Hour,
+		ResetAfter:      24 * time.Hour,
+	}
+)
+
+// retryAfter returns how long to wait before the next attempt, or 0.
+func (p loginPolicy) retryAfter(attempts *types.LoginAttempts, now time.Time) time.Duration {
+	if attempts == nil || p.isStale(attempts, now) {
+		return 0
+	}
+
+	if attempts.LockedUntil != nil && now.Before(*attempts.LockedUntil) {
+		return attempts.LockedUntil.Sub(now)
+	}
+
+	if attempts.Failures < p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.MaxDelay
+	if extra := attempts.Failures - p.FreeAttempts; extra < 32 {
+		if d := p.BaseDelay << extra; d > 0 && d < p.MaxDelay {
+			delay = d
+		}
+	}
+
+	if next := attempts.LastFailureAt.Add(delay); now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+// isSpent reports whether the failures no longer count: they are too old, or
+// their lockout has been served.
+func (p loginPolicy) isSpent(attempts *types.LoginAttempts, now time.Time) bool {
+	if attempts.LockedUntil != nil && !now.Before(*attempts.LockedUntil) {
+		return true
+	}
+	return p.isStale(attempts, now)
+}
+
+func (p loginPolicy) isStale(attempts *types.LoginAttempts, now time.Time) bool {
+	return now.Sub(attempts.LastFailureAt) > p.ResetAfter
+}
+
+// loginThrottle names the counters a login attempt is held against.
+type loginThrottle struct {
+	key    string
+	policy loginPolicy
+}
+
+func accountLoginKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+func loginThrottles(params AuthParams) []loginThrottle {
+	throttles := []loginThrottle{{key: accountLoginKey(params.Email), policy: accountLoginPolicy}}
+	if params.ClientIP != "" {
+		throttles = append(throttles, loginThrottle{key: ipLoginKey(params.ClientIP), policy: ipLoginPolicy})
+	}
+	return throttles
+}
+
+// checkLoginThrottles returns a *types.LoginThrottledError if any of the
+// throttles asks the client to wait.
+func (m *Manager) checkLoginThrottles(ctx context.Context, throttles []loginThrottle) error {
+	now := time.Now()
+
+	var wait time.Duration
+	for _, t := range throttles {
+		attempts, err := m.LoginAttemptStore.GetLoginAttempts(ctx, t.key)
+		if errors.Is(err, types.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if d := t.policy.retryAfter(attempts, now); d > wait {
+			wait = d
+		}
+	}
+
+	if wait > 0 {
+		return &types.LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordLoginFailure counts the failure against every throttle and locks
+// those that reached their lockout.
+func (m *Manager) recordLoginFailure(ctx context.Context, throttles []loginThrottle) error {
+	now := time.Now()
+
+	for _, t := range throttles {
+		attempts, err := m.LoginAttemptStore.GetLoginAttempts(ctx, t.key)
+		if err != nil && !errors.Is(err, types.ErrNotFound) {
+			return err
+		}
+		if attempts != nil && t.policy.isSpent(attempts, now) {
+			if err := m.LoginAttemptStore.ResetLoginAttempts(ctx, t.key); err != nil {
+				return err
+			}
+		}
+
+		attempts, err = m.LoginAttemptStore.RecordLoginFailure(ctx, t.key, now)
+		if err != nil {
+			return err
+		}
+
+		if attempts.Failures >= t.policy.LockoutAttempts {
+			if err := m.LoginAttemptStore.LockLogin(ctx, t.key, now.Add(t.policy.LockoutDuration)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnlockAccount forgets the failed logins of the user, lifting any lockout of
+// their account. Lockouts of client IPs stay.
+func (m *Manager) UnlockAccount(ctx context.Context, userID string) error {
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return m.LoginAttemptStore.ResetLoginAttempts(ctx, accountLoginKey(user.Email))
+}
+
+var (
+	dummyPasswordOnce sync.Once
+	dummyPassword     string
+)
+
+// compareDummyPassword spends as long as checking a real password, so an
+// unknown email does not answer faster than a known one.
+func compareDummyPassword(password string) {
+	dummyPasswordOnce.Do(func() {
+		dummyPassword, _ = types.HashPassword("not a real password")
+	})
+	types.IsValidPassword(dummyPassword, password)
+}
@@ -0,0 +1,134 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestLoginPolicyRetryAfter(t *testing.T) {
+	now := time.Date(2027, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	lockedUntil := now.Add(10 * time.Minute)
+	lockEnded := ago(time.Minute)
+
+	tests := []struct {
+		name     string
+		attempts *types.LoginAttempts
+		want     time.Duration
+	}{
+		{"no failures", nil, 0},
+		{"free attempts left", &types.LoginAttempts{Failures: 2, LastFailureAt: now}, 0},
+		{"first delay", &types.LoginAttempts{Failures: 3, LastFailureAt: now}, time.Second},
+		{"delay doubles", &types.LoginAttempts{Failures: 5, LastFailureAt: now}, 4 * time.Second},
+		{"last delay before the lockout", &types.LoginAttempts{Failures: 9, LastFailureAt: now}, 64 * time.Second},
+		{"delay counts from the last failure", &types.LoginAttempts{Failures: 5, LastFailureAt: ago(3 * time.Second)}, time.Second},
+		{"delay has passed", &types.LoginAttempts{Failures: 5, LastFailureAt: ago(time.Minute)}, 0},
+		{"locked", &types.LoginAttempts{Failures: 10, LastFailureAt: ago(20 * time.Minute), LockedUntil: &lockedUntil}, 10 * time.Minute},
+		{"lock has ended", &types.LoginAttempts{Failures: 10, LastFailureAt: ago(31 * time.Minute), LockedUntil: &lockEnded}, 0},
+		{"failures are stale", &types.LoginAttempts{Failures: 9, LastFailureAt: ago(25 * time.Hour)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accountLoginPolicy.retryAfter(tt.attempts, now); got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// IPs get enough attempts before their lockout to reach the longest delay
+	capped := &types.LoginAttempts{Failures: 40, LastFailureAt: now}
+	if got := ipLoginPolicy.retryAfter(capped, now); got != ipLoginPolicy.MaxDelay {
+		t.Fatalf("capped delay: got %s, want %s", got, ipLoginPolicy.MaxDelay)
+	}
+}
+
+func TestGetUserTokenThrottles(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	if _, err := m.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"}); err != nil {
+		t.Fatal(err)
+	}
+	wrong := AuthParams{Email: "ali@example.com", Password: "wrong password", ClientIP: "192.0.2.1"}
+
+	for i := 0; i < accountLoginPolicy.FreeAttempts; i++ {
+		if _, _, err := m.GetUserToken(ctx, wrong); !errors.Is(err, types.ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: got %v, want %v", i+1, err, types.ErrInvalidCredentials)
+		}
+	}
+
+	// Even the right password has to wait now
+	_, _, err := m.GetUserToken(ctx, AuthParams{Email: "ali@example.com", Password: "password"})
+	var throttled *types.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("got %v, want a %T", err, throttled)
+	}
+	if throttled.RetryAfter <= 0 || throttled.RetryAfter > accountLoginPolicy.BaseDelay {
+		t.Fatalf("got retry after %s, want at most %s", throttled.RetryAfter, accountLoginPolicy.BaseDelay)
+	}
+}
+
+func TestRecordLoginFailureLocks(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	throttles := loginThrottles(AuthParams{Email: "ali@example.com"})
+	key := accountLoginKey("ali@example.com")
+
+	// One failure short of the lockout, spread out so no delay applies
+	for i := 1; i < accountLoginPolicy.LockoutAttempts; i++ {
+		if _, err := m.LoginAttemptStore.RecordLoginFailure(ctx, key, time.Now().Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.checkLoginThrottles(ctx, throttles); err != nil {
+		t.Fatalf("before the lockout: got %v", err)
+	}
+
+	if err := m.recordLoginFailure(ctx, throttles); err != nil {
+		t.Fatal(err)
+	}
+	var throttled *types.LoginThrottledError
+	if err := m.checkLoginThrottles(ctx, throttles); !errors.As(err, &throttled) || throttled.RetryAfter < accountLoginPolicy.LockoutDuration-time.Minute {
+		t.Fatalf("got %v, want a lockout of %s", err, accountLoginPolicy.LockoutDuration)
+	}
+}
+
+func TestRecordLoginFailureAfterLockExpires(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	throttles := loginThrottles(AuthParams{Email: "ali@example.com"})
+	key := accountLoginKey("ali@example.com")
+
+	// A lockout that ended a minute ago
+	lockedAt := time.Now().Add(-accountLoginPolicy.LockoutDuration - time.Minute)
+	for i := 0; i < accountLoginPolicy.LockoutAttempts; i++ {
+		if _, err := m.LoginAttemptStore.RecordLoginFailure(ctx, key, lockedAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.LoginAttemptStore.LockLogin(ctx, key, lockedAt.Add(accountLoginPolicy.LockoutDuration)); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.checkLoginThrottles(ctx, throttles); err != nil {
+		t.Fatalf("after the lockout: got %v", err)
+	}
+
+	// The next failure starts counting afresh instead of locking again
+	if err := m.recordLoginFailure(ctx, throttles); err != nil {
+		t.Fatal(err)
+	}
+	attempts, err := m.LoginAttemptStore.GetLoginAttempts(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts.Failures != 1 || attempts.LockedUntil != nil {
+		t.Fatalf("got %d failures locked until %v, want 1 failure and no lock", attempts.Failures, attempts.LockedUntil)
+	}
+	if err := m.checkLoginThrottles(ctx, throttles); err != nil {
+		t.Fatalf("after one more failure: got %v", err)
+	}
+}
@@ -1,21 +1,22 @@
 package business
 
 import (
+	"github.com/mkabdelrahman/hotel-reservation/auth"
 	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/oidc"
 )
 
 type Manager struct {
-	HotelStore   db.HotelStore
-	RoomStore    db.RoomStore
-	UserStore    db.UserStore
-	BookingStore db.BookingStore
+	db.Stores
+
+	// Tokens signs access tokens; it must be set before users log in.
+	Tokens *auth.Issuer
+	// PublicURL is where users reach the API, for links sent to them.
+	PublicURL string
+	// OIDCProviders are the identity providers users may log in with, by name.
+	OIDCProviders map[string]*oidc.Provider
 }
 
-func NewManager(userStore db.UserStore, hotelStore db.HotelStore, roomStore db.RoomStore, bookingStore db.BookingStore) *Manager {
-	return &Manager{
-		UserStore:    userStore,
-		HotelStore:   hotelStore,
-		RoomStore:    roomStore,
-		BookingStore: bookingStore,
-	}
+func NewManager(stores db.Stores) *Manager {
+	return &Manager{Stores: stores}
 }
@@ -0,0 +1,96 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/db/memory"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newTestManager returns a manager over empty in-memory stores that can issue
+// tokens.
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	m := NewManager(memory.NewStores())
+
+	key, err := auth.NewHMACKey("test", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Tokens, err = auth.NewIssuer(auth.Config{Issuer: "hotel-reservation", Audience: "hotel-reservation", AccessTokenTTL: time.Minute, Keys: []auth.Key{key}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// newMongoTestManager is newTestManager with the user, hotel, room and booking
+// stores in a fresh MongoDB database at DB_URI, which is dropped when the test
+// ends. It skips the test when DB_URI is not set.
+func newMongoTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	uri := os.Getenv("DB_URI")
+	if uri == "" {
+		t.Skip("DB_URI is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbName := fmt.Sprintf("hotel-reservation-test-%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		client.Database(dbName).Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	m := newTestManager(t)
+	m.UserStore = db.NewMongoUserStore(client, dbName, "users")
+	m.HotelStore = db.NewMongoHotelStore(client, dbName, "hotels", "rooms", "bookings")
+	m.RoomStore = db.NewMongoRoomStore(client, dbName, "rooms")
+	m.BookingStore = db.NewMongoBookingStore(client, dbName, "bookings")
+	return m
+}
+
+// addTestHotel adds a hotel with a standard room of each number, returning the
+// hotel and room IDs.
+func addTestHotel(t *testing.T, m *Manager, numbers ...string) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	hotelID, err := m.AddNewHotel(ctx, types.NewHotelParams{Name: "Dolcica", Location: "Madrid", Rating: types.Excellent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roomIDs []string
+	for _, number := range numbers {
+		roomID, err := m.AddNewRoom(ctx, types.NewRoomParams{Number: number, Type: types.StandardRoom, Price: 100}, hotelID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roomIDs = append(roomIDs, roomID)
+	}
+	return hotelID, roomIDs
+}
+
+// addTestUser registers an active user with the password "password".
+func addTestUser(t *testing.T, m *Manager, email string) string {
+	t.Helper()
+
+	userID, err := m.AddNewUser(context.Background(), types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: email, Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return userID
+}
@@ -0,0 +1,313 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// MFAChallengeTTL bounds the time between a correct password and the code.
+const MFAChallengeTTL = 5 * time.Minute
+
+const (
+	mfaChallengePurpose = "mfa_challenge"
+	// totpIssuer names the account in authenticator apps
+	totpIssuer        = "Hotel Reservation"
+	recoveryCodeCount = 10
+)
+
+// mfaChallenge returns the challenge to answer after the password, or nil if
+// the user logs in with the password alone.
+func (m *Manager) mfaChallenge(ctx context.Context, user *types.User) (*types.MFAChallenge, error) {
+	required, err := m.mfaRequired(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if !required && !user.MFAEnabled() {
+		return nil, nil
+	}
+
+	token, err := m.Tokens.SignLinkToken(mfaChallengePurpose, user.ID.Hex(), MFAChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MFAChallenge{
+		MFARequired:        true,
+		Token:              token,
+		ExpiresAt:          time.Now().UTC().Add(MFAChallengeTTL),
+		EnrollmentRequired: !user.MFAEnabled(),
+	}, nil
+}
+
+func (m *Manager) mfaRequired(ctx context.Context, user *types.User) (bool, error) {
+	policy, err := m.SettingsStore.GetMFAPolicy(ctx)
+	if err != nil {
+		return false, err
+	}
+	return policy.Requires(user.EffectiveRole()), nil
+}
+
+// CompleteMFALogin finishes a login with the code for its challenge. For a
+// user whose role requires MFA and who set up TOTP with the challenge, the
+// code confirms it and the one-time recovery codes come with the tokens.
+// Wrong codes count as failed logins.
+func (m *Manager) CompleteMFALogin(ctx context.Context, params types.MFALoginParams, clientIP string) (*types.AuthTokens, error) {
+	userID, err := m.Tokens.ParseLinkToken(mfaChallengePurpose, params.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := user.EffectiveStatus().CanLogIn(); err != nil {
+		return nil, err
+	}
+
+	throttles := loginThrottles(AuthParams{Email: user.Email, ClientIP: clientIP})
+	if err := m.checkLoginThrottles(ctx, throttles); err != nil {
+		return nil, err
+	}
+
+	if user.MFA == nil {
+		return nil, types.ErrMFANotEnrolled
+	}
+
+	var recoveryCodes []string
+	if user.MFAEnabled() {
+		err = m.verifyMFACode(ctx, user, params.Code)
+	} else {
+		recoveryCodes, err = m.enableTOTP(ctx, user, params.Code)
+	}
+	if errors.Is(err, types.ErrInvalidMFACode) {
+		if err := m.recordLoginFailure(ctx, throttles); err != nil {
+			return nil, err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.LoginAttemptStore.ResetLoginAttempts(ctx, accountLoginKey(user.Email)); err != nil {
+		return nil, err
+	}
+
+	tokens, err := m.startSession(ctx, user, true)
+	if err != nil {
+		return nil, err
+	}
+	tokens.RecoveryCodes = recoveryCodes
+	return tokens, nil
+}
+
+// EnrollTOTPWithChallenge starts setting up TOTP for a user who has to before
+// logging in. CompleteMFALogin with a code from the app confirms it.
+func (m *Manager) EnrollTOTPWithChallenge(ctx context.Context, params types.MFAEnrollParams) (*types.TOTPEnrollment, error) {
+	userID, err := m.Tokens.ParseLinkToken(mfaChallengePurpose, params.Token)
+	if err != nil {
+		return nil, err
+	}
+	return m.EnrollTOTP(ctx, userID)
+}
+
+// EnrollTOTP gives the user a new TOTP secret, replacing any pending one.
+// It is not asked for at login until ConfirmTOTP.
+func (m *Manager) EnrollTOTP(ctx context.Context, userID string) (*types.TOTPEnrollment, error) {
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, types.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.UserStore.UpdateUserMFA(ctx, userID, &types.UserMFA{Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &types.TOTPEnrollment{
+		Secret: secret,
+		URI:    auth.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP turns on the pending TOTP secret once the code shows the app
+// has it, and returns the recovery codes, which are shown only this once.
+func (m *Manager) ConfirmTOTP(ctx context.Context, userID string, params types.MFACodeParams) ([]string, error) {
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled() {
+		return nil, types.ErrMFAAlreadyEnabled
+	}
+	if user.MFA == nil {
+		return nil, types.ErrMFANotEnrolled
+	}
+
+	return m.enableTOTP(ctx, user, params.Code)
+}
+
+func (m *Manager) enableTOTP(ctx context.Context, user *types.User, code string) ([]string, error) {
+	step, ok := auth.ValidateTOTP(user.MFA.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return nil, types.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	enabledAt := time.Now().UTC()
+	mfa := &types.UserMFA{
+		Secret:             user.MFA.Secret,
+		Enabled:            true,
+		EnabledAt:          &enabledAt,
+		LastUsedStep:       step,
+		RecoveryCodeHashes: hashes,
+	}
+	if err := m.UserStore.UpdateUserMFA(ctx, user.ID.Hex(), mfa); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes with new ones,
+// for when they ran low or were exposed.
+func (m *Manager) RegenerateRecoveryCodes(ctx context.Context, userID string, params types.MFACodeParams) ([]string, error) {
+	user, err := m.enabledMFAUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verifyMFACode(ctx, user, params.Code); err != nil {
+		return nil, err
+	}
+
+	// Reload so the step just used is kept
+	if user, err = m.enabledMFAUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	mfa := *user.MFA
+	mfa.RecoveryCodeHashes = hashes
+	if err := m.UserStore.UpdateUserMFA(ctx, userID, &mfa); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableMFA turns off the user's second factor, given a current code. Users
+// whose role requires MFA cannot.
+func (m *Manager) DisableMFA(ctx context.Context, userID string, params types.MFACodeParams) error {
+	user, err := m.enabledMFAUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := m.mfaRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return types.ErrMFARequired
+	}
+
+	if err := m.verifyMFACode(ctx, user, params.Code); err != nil {
+		return err
+	}
+	return m.UserStore.UpdateUserMFA(ctx, userID, nil)
+}
+
+// ResetMFA removes the user's second factor, as for a lost phone, and logs
+// them out everywhere. If their role requires MFA they set it up again at
+// their next login.
+func (m *Manager) ResetMFA(ctx context.Context, userID string) error {
+	if err := m.UserStore.UpdateUserMFA(ctx, userID, nil); err != nil {
+		return err
+	}
+	return m.RevokeAllSessions(ctx, userID)
+}
+
+func (m *Manager) GetMFAPolicy(ctx context.Context) (*types.MFAPolicy, error) {
+	return m.SettingsStore.GetMFAPolicy(ctx)
+}
+
+// SetMFAPolicy sets the roles that must use MFA. Sessions of those users that
+// did not pass it end at their next refresh.
+func (m *Manager) SetMFAPolicy(ctx context.Context, changedBy string, params types.SetMFAPolicyParams) (*types.MFAPolicy, error) {
+	policy := &types.MFAPolicy{
+		RequiredRoles: params.RequiredRoles,
+		UpdatedBy:     changedBy,
+		UpdatedAt:     time.Now().UTC(),
+	}
+	if policy.RequiredRoles == nil {
+		policy.RequiredRoles = []types.Role{}
+	}
+
+	if err := m.SettingsStore.UpdateMFAPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (m *Manager) enabledMFAUser(ctx context.Context, userID string) (*types.User, error) {
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled() {
+		return nil, types.ErrMFANotEnrolled
+	}
+	return user, nil
+}
+
+// verifyMFACode accepts a TOTP code not used before or an unused recovery
+// code, which is then used up.
+func (m *Manager) verifyMFACode(ctx context.Context, user *types.User, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := auth.ValidateTOTP(user.MFA.Secret, code, time.Now()); ok {
+		return m.UserStore.UseMFAStep(ctx, user.ID.Hex(), step)
+	}
+	return m.UserStore.UseRecoveryCode(ctx, user.ID.Hex(), auth.HashToken(normalizeRecoveryCode(code)))
+}
+
+// newRecoveryCodes returns codes formatted for reading, like
+// "k3j7-x2mq-5wzt-p4ra", and the hashes that are stored.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret, err := auth.NewTOTPSecret()
+		if err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(secret[:16])
+
+		codes = append(codes, raw[:4]+"-"+raw[4:8]+"-"+raw[8:12]+"-"+raw[12:])
+		hashes = append(hashes, auth.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return strings.ToLower(code)
+}
@@ -0,0 +1,108 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// enrollTestTOTP registers a user with TOTP turned on, returning their
+// login, secret, the code that confirmed it and their recovery codes.
+func enrollTestTOTP(t *testing.T, m *Manager) (AuthParams, string, string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	login := AuthParams{Email: "ali@example.com", Password: "password"}
+	userID, err := m.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: login.Email, Password: login.Password})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enrollment, err := m.EnrollTOTP(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := auth.TOTPCode(enrollment.Secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryCodes, err := m.ConfirmTOTP(ctx, userID, types.MFACodeParams{Code: code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recoveryCodes), recoveryCodeCount)
+	}
+	return login, enrollment.Secret, code, recoveryCodes
+}
+
+// completeTestMFALogin logs in with the password and answers the challenge
+// with code.
+func completeTestMFALogin(t *testing.T, m *Manager, login AuthParams, code string) error {
+	t.Helper()
+	ctx := context.Background()
+
+	tokens, challenge, err := m.GetUserToken(ctx, login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens != nil || challenge == nil {
+		t.Fatal("got tokens for the password alone, want an MFA challenge")
+	}
+
+	tokens, err = m.CompleteMFALogin(ctx, types.MFALoginParams{Token: challenge.Token, Code: code}, "")
+	if err == nil && tokens == nil {
+		t.Fatal("got no tokens for a correct code")
+	}
+	return err
+}
+
+func TestCompleteMFALoginRejectsReplayedCode(t *testing.T) {
+	m := newTestManager(t)
+	login, secret, confirmCode, _ := enrollTestTOTP(t, m)
+
+	// The code that confirmed TOTP is used up, though still in its window
+	if err := completeTestMFALogin(t, m, login, confirmCode); !errors.Is(err, types.ErrInvalidMFACode) {
+		t.Fatalf("confirming code: got %v, want %v", err, types.ErrInvalidMFACode)
+	}
+
+	next, err := auth.TOTPCode(secret, time.Now().Add(30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := completeTestMFALogin(t, m, login, next); err != nil {
+		t.Fatalf("next code: %v", err)
+	}
+	if err := completeTestMFALogin(t, m, login, next); !errors.Is(err, types.ErrInvalidMFACode) {
+		t.Fatalf("next code again: got %v, want %v", err, types.ErrInvalidMFACode)
+	}
+
+	// Nor is an earlier code accepted once a later one was used
+	if err := completeTestMFALogin(t, m, login, confirmCode); !errors.Is(err, types.ErrInvalidMFACode) {
+		t.Fatalf("earlier code: got %v, want %v", err, types.ErrInvalidMFACode)
+	}
+}
+
+func TestCompleteMFALoginUsesRecoveryCodeOnce(t *testing.T) {
+	m := newTestManager(t)
+	login, _, _, recoveryCodes := enrollTestTOTP(t, m)
+
+	// Codes are accepted however they are typed
+	typed := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", " "))
+	if err := completeTestMFALogin(t, m, login, typed); err != nil {
+		t.Fatalf("recovery code: %v", err)
+	}
+	if err := completeTestMFALogin(t, m, login, recoveryCodes[0]); !errors.Is(err, types.ErrInvalidMFACode) {
+		t.Fatalf("recovery code again: got %v, want %v", err, types.ErrInvalidMFACode)
+	}
+
+	// The others are left
+	if err := completeTestMFALogin(t, m, login, recoveryCodes[1]); err != nil {
+		t.Fatalf("second recovery code: %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package business
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/notify"
+)
+
+const (
+	// notificationMaxAttempts is how often a notification is tried before it
+	// is left in the outbox for good.
+	notificationMaxAttempts = 5
+	notificationBatchSize   = 50
+)
+
+// DispatchNotifications sends the pending notifications in the outbox and
+// returns how many were sent. A notification that fails is retried on the
+// next call, up to notificationMaxAttempts times, unless it expires first.
+func (m *Manager) DispatchNotifications(ctx context.Context, sender notify.Sender) (int, error) {
+	if _, err := m.OutboxStore.ExpireNotifications(ctx, time.Now().UTC()); err != nil {
+		return 0, err
+	}
+
+	pending, err := m.OutboxStore.GetPendingNotifications(ctx, notificationMaxAttempts, notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var sent int
+	for _, notification := range pending {
+		msg := notify.Message{
+			To:      notification.To,
+			Subject: notification.Subject,
+			Body:    notification.Body,
+		}
+		if err := sender.Send(ctx, msg); err != nil {
+			if err := m.OutboxStore.RecordNotificationFailure(ctx, notification.ID, err.Error()); err != nil {
+				return sent, err
+			}
+			continue
+		}
+
+		if err := m.OutboxStore.MarkNotificationSent(ctx, notification.ID, time.Now().UTC()); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// RunNotificationDispatch calls DispatchNotifications every interval until
+// ctx is done. Errors are logged and retried on the next tick.
+func (m *Manager) RunNotificationDispatch(ctx context.Context, sender notify.Sender, interval time.Duration, errorLogger *log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.DispatchNotifications(ctx, sender); err != nil && ctx.Err() == nil {
+				errorLogger.Printf("Error dispatching notifications: %v", err)
+			}
+		}
+	}
+}
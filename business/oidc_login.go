@@ -0,0 +1,184 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/oidc"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// OIDCLoginTTL bounds the time a user has to log in at the provider.
+const OIDCLoginTTL = 10 * time.Minute
+
+// StartOIDCLogin begins logging in with the named provider and returns the
+// provider's URL to send the user to, and the state the provider will send
+// back. The caller ties the state to the user's browser, so a login started
+// elsewhere cannot be completed in it.
+func (m *Manager) StartOIDCLogin(ctx context.Context, providerName string) (authURL string, state string, err error) {
+	provider, ok := m.OIDCProviders[providerName]
+	if !ok {
+		return "", "", types.ErrUnknownProvider
+	}
+
+	state, err = oidc.NewState()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	login := &types.OIDCLogin{
+		Provider:     providerName,
+		StateHash:    auth.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    time.Now().UTC(),
+		ExpiresAt:    time.Now().UTC().Add(OIDCLoginTTL),
+	}
+	if _, err := m.OIDCLoginStore.InsertOIDCLogin(ctx, login); err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin ends a login the provider sent back with state and code.
+// The ID token's subject finds the user it was linked to before; otherwise a
+// verified email links the user with that email, or creates one. Like
+// GetUserToken it returns tokens, or a challenge when MFA applies.
+func (m *Manager) CompleteOIDCLogin(ctx context.Context, providerName, state, code string) (*types.AuthTokens, *types.MFAChallenge, error) {
+	provider, ok := m.OIDCProviders[providerName]
+	if !ok {
+		return nil, nil, types.ErrUnknownProvider
+	}
+
+	login, err := m.OIDCLoginStore.TakeOIDCLogin(ctx, auth.HashToken(state), time.Now().UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+	if login.Provider != providerName {
+		return nil, nil, types.ErrInvalidOIDCState
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	idToken, err := provider.VerifyIDToken(ctx, rawIDToken, login.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := m.oidcUser(ctx, providerName, idToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := user.EffectiveStatus().CanLogIn(); err != nil {
+		return nil, nil, err
+	}
+
+	challenge, err := m.mfaChallenge(ctx, user)
+	if err != nil || challenge != nil {
+		return nil, challenge, err
+	}
+
+	tokens, err := m.startSession(ctx, user, false)
+	return tokens, nil, err
+}
+
+func (m *Manager) oidcUser(ctx context.Context, providerName string, idToken *oidc.IDToken) (*types.User, error) {
+	user, err := m.UserStore.GetUserByIdentity(ctx, providerName, idToken.Subject)
+	if err == nil || !errors.Is(err, types.ErrNotFound) {
+		return user, err
+	}
+
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return nil, types.ErrOIDCEmailNotVerified
+	}
+
+	identity := types.ExternalIdentity{
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		LinkedAt: time.Now().UTC(),
+	}
+
+	user, err = m.UserStore.GetUserByEmail(ctx, idToken.Email)
+	if errors.Is(err, types.ErrNotFound) {
+		return m.addOIDCUser(ctx, idToken, identity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	userID := user.ID.Hex()
+	status := user.EffectiveStatus()
+	if status != types.AccountPendingVerification {
+		if err := status.CanLogIn(); err != nil {
+			return nil, err
+		}
+	} else if err := m.claimPendingUser(ctx, userID, providerName); err != nil {
+		return nil, err
+	}
+
+	if err := m.UserStore.AddUserIdentity(ctx, userID, identity); err != nil {
+		return nil, err
+	}
+
+	return m.UserStore.GetUserByID(ctx, userID)
+}
+
+// claimPendingUser activates an account whose email the provider vouches for
+// but nobody has confirmed yet. Whoever registered it never proved they own
+// the email, so their password is dropped and their sessions end; the owner
+// sets a password with a password reset.
+func (m *Manager) claimPendingUser(ctx context.Context, userID string, providerName string) error {
+	if err := m.UserStore.UpdateUserPassword(ctx, userID, ""); err != nil {
+		return err
+	}
+	if err := m.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	change := types.AccountStatusChange{
+		Reason:    "email verified by " + providerName,
+		ChangedBy: userID,
+		ChangedAt: time.Now().UTC(),
+	}
+	return m.UserStore.UpdateUserStatus(ctx, userID, types.AccountActive, change)
+}
+
+// addOIDCUser creates the user of an identity on first login. They have no
+// password, so they log in through the provider only, until they reset one.
+func (m *Manager) addOIDCUser(ctx context.Context, idToken *oidc.IDToken, identity types.ExternalIdentity) (*types.User, error) {
+	firstName, lastName := idToken.GivenName, idToken.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(idToken.Name, " ")
+	}
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(idToken.Email, "@")
+	}
+
+	user := &types.User{
+		FirstName:  firstName,
+		LastName:   lastName,
+		Email:      idToken.Email,
+		Status:     types.AccountActive,
+		Identities: []types.ExternalIdentity{identity},
+	}
+	return m.UserStore.InsertUser(ctx, user)
+}
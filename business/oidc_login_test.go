@@ -0,0 +1,142 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mkabdelrahman/hotel-reservation/oidc"
+	"github.com/mkabdelrahman/hotel-reservation/oidc/oidctest"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	ctx := context.Background()
+	mock := oidctest.NewProvider("hotel", "secret")
+	defer mock.Close()
+
+	m := newTestManager(t)
+	provider, err := oidc.NewProvider(oidc.Config{Issuer: mock.Issuer(), ClientID: "hotel", ClientSecret: "secret", RedirectURL: "http://localhost/cb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.OIDCProviders = map[string]*oidc.Provider{"mock": provider}
+
+	// login runs the flow as the user and returns the state it used
+	login := func(t *testing.T, user oidctest.User) (*types.AuthTokens, string, error) {
+		t.Helper()
+		mock.SetUser(user)
+		authURL, _, err := m.StartOIDCLogin(ctx, "mock")
+		if err != nil {
+			t.Fatal(err)
+		}
+		redirect, err := mock.Authorize(authURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, code := redirect.Query().Get("state"), redirect.Query().Get("code")
+		tokens, _, err := m.CompleteOIDCLogin(ctx, "mock", state, code)
+		return tokens, state, err
+	}
+	userOf := func(t *testing.T, tokens *types.AuthTokens) string {
+		t.Helper()
+		claims, err := m.AuthenticateAccessToken(ctx, tokens.AccessToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return claims.Subject
+	}
+
+	t.Run("creates a user and logs in again as the same user", func(t *testing.T) {
+		identity := oidctest.User{Subject: "sub-1", Email: "new@example.com", EmailVerified: true, GivenName: "Nour", FamilyName: "Adel"}
+		tokens, state, err := login(t, identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		userID := userOf(t, tokens)
+
+		user, err := m.UserStore.GetUserByEmail(ctx, "new@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID.Hex() != userID || user.FirstName != "Nour" || user.Status != types.AccountActive {
+			t.Fatalf("got user %+v for token subject %s", user, userID)
+		}
+
+		if _, _, err := m.CompleteOIDCLogin(ctx, "mock", state, "any"); !errors.Is(err, types.ErrInvalidOIDCState) {
+			t.Fatalf("reused state: got %v, want %v", err, types.ErrInvalidOIDCState)
+		}
+
+		// The subject, not the email, identifies the user from now on
+		identity.Email = "changed@example.com"
+		tokens, _, err = login(t, identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := userOf(t, tokens); got != userID {
+			t.Fatalf("second login as %s, want %s", got, userID)
+		}
+	})
+
+	t.Run("links an existing user by verified email", func(t *testing.T) {
+		userID, err := m.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens, _, err := login(t, oidctest.User{Subject: "sub-2", Email: "ali@example.com", EmailVerified: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := userOf(t, tokens); got != userID {
+			t.Fatalf("logged in as %s, want %s", got, userID)
+		}
+	})
+
+	t.Run("takes a pending account from whoever registered it", func(t *testing.T) {
+		userID, err := m.RegisterUser(ctx, types.NewUserParams{FirstName: "Mallory", LastName: "Smith", Email: "victim@example.com", Password: "attacker-password"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens, _, err := login(t, oidctest.User{Subject: "sub-4", Email: "victim@example.com", EmailVerified: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := userOf(t, tokens); got != userID {
+			t.Fatalf("logged in as %s, want %s", got, userID)
+		}
+
+		_, _, err = m.GetUserToken(ctx, AuthParams{Email: "victim@example.com", Password: "attacker-password"})
+		if !errors.Is(err, types.ErrInvalidCredentials) {
+			t.Fatalf("registrant's password: got %v, want %v", err, types.ErrInvalidCredentials)
+		}
+	})
+
+	t.Run("does not link a suspended user", func(t *testing.T) {
+		userID, err := m.AddNewUser(ctx, types.NewUserParams{FirstName: "Sam", LastName: "Ola", Email: "suspended@example.com", Password: "password"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := m.SetAccountStatus(ctx, userID, userID, types.SetAccountStatusParams{Status: types.AccountSuspended, Reason: "test"}); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = login(t, oidctest.User{Subject: "sub-5", Email: "suspended@example.com", EmailVerified: true})
+		if !errors.Is(err, types.ErrAccountSuspended) {
+			t.Fatalf("got %v, want %v", err, types.ErrAccountSuspended)
+		}
+
+		user, err := m.UserStore.GetUserByID(ctx, userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(user.Identities) != 0 {
+			t.Fatalf("got identities %+v, want none", user.Identities)
+		}
+	})
+
+	t.Run("refuses an unverified email", func(t *testing.T) {
+		_, _, err := login(t, oidctest.User{Subject: "sub-3", Email: "unverified@example.com"})
+		if !errors.Is(err, types.ErrOIDCEmailNotVerified) {
+			t.Fatalf("got %v, want %v", err, types.ErrOIDCEmailNotVerified)
+		}
+	})
+}
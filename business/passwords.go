@@ -0,0 +1,114 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// PasswordResetTTL bounds how long a password reset code can be used.
+const PasswordResetTTL = time.Hour
+
+// ChangePassword sets a new password for a user who knows the current one,
+// and logs them out everywhere.
+func (m *Manager) ChangePassword(ctx context.Context, userID string, params types.ChangePasswordParams) error {
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !isValidPassword(user, params.CurrentPassword) {
+		return types.ErrWrongPassword
+	}
+
+	return m.setPassword(ctx, userID, params.NewPassword)
+}
+
+// RequestPasswordReset puts a single-use reset code for the user with the
+// email in the outbox. An unknown email is not an error, so the caller cannot
+// learn which emails are registered.
+func (m *Manager) RequestPasswordReset(ctx context.Context, params types.PasswordResetRequestParams) error {
+	user, err := m.UserStore.GetUserByEmail(ctx, params.Email)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	code, err := auth.NewRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	token := &types.UserToken{
+		UserID:    user.ID.Hex(),
+		Purpose:   types.TokenPasswordReset,
+		TokenHash: auth.HashToken(code),
+		CreatedAt: now,
+		ExpiresAt: now.Add(PasswordResetTTL),
+	}
+	if _, err := m.UserTokenStore.InsertUserToken(ctx, token); err != nil {
+		return err
+	}
+
+	notification := &types.Notification{
+		Kind:    types.NotificationPasswordReset,
+		UserID:  token.UserID,
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Use this code to reset your password: %s\n\nIt expires in %s. If you did not ask to reset your password, ignore this message.",
+			code, PasswordResetTTL),
+		CreatedAt: now,
+		ExpiresAt: token.ExpiresAt,
+	}
+	_, err = m.OutboxStore.InsertNotification(ctx, notification)
+	return err
+}
+
+// ResetPassword sets a new password with a code from RequestPasswordReset.
+// The code, and any other reset code of the user, cannot be used again.
+func (m *Manager) ResetPassword(ctx context.Context, params types.PasswordResetParams) error {
+	token, err := m.UserTokenStore.GetUserTokenByHash(ctx, types.TokenPasswordReset, auth.HashToken(params.Token))
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return types.ErrInvalidUserToken
+		}
+		return err
+	}
+
+	now := time.Now().UTC()
+	if !token.IsUsable(now) {
+		return types.ErrInvalidUserToken
+	}
+
+	if err := m.UserTokenStore.UseUserToken(ctx, token.ID, now); err != nil {
+		return err
+	}
+
+	if err := m.setPassword(ctx, token.UserID, params.NewPassword); err != nil {
+		return err
+	}
+
+	return m.UserTokenStore.UseUserTokens(ctx, token.UserID, types.TokenPasswordReset, now)
+}
+
+// setPassword stores the new password's hash and revokes every session, so
+// whoever knew the old password loses access.
+func (m *Manager) setPassword(ctx context.Context, userID string, password string) error {
+	encryptedPassword, err := types.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := m.UserStore.UpdateUserPassword(ctx, userID, encryptedPassword); err != nil {
+		return err
+	}
+
+	return m.RevokeAllSessions(ctx, userID)
+}
@@ -0,0 +1,61 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// sentResetCode returns the code of the password reset waiting in the outbox.
+func sentResetCode(t *testing.T, m *Manager) string {
+	t.Helper()
+
+	pending, err := m.OutboxStore.GetPendingNotifications(context.Background(), 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, notification := range pending {
+		if notification.Kind != types.NotificationPasswordReset {
+			continue
+		}
+		_, rest, _ := strings.Cut(notification.Body, "reset your password: ")
+		code, _, _ := strings.Cut(rest, "\n")
+		return code
+	}
+	t.Fatal("no password reset in the outbox")
+	return ""
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	tokens := logInTestUser(t, m)
+
+	if err := m.RequestPasswordReset(ctx, types.PasswordResetRequestParams{Email: "ali@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	code := sentResetCode(t, m)
+
+	if err := m.ResetPassword(ctx, types.PasswordResetParams{Token: code, NewPassword: "new password"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Whoever had the old password is logged out
+	if _, err := m.AuthenticateAccessToken(ctx, tokens.AccessToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("access token: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+	if _, err := m.RefreshSession(ctx, tokens.RefreshToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("refresh token: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+	if _, _, err := m.GetUserToken(ctx, AuthParams{Email: "ali@example.com", Password: "new password"}); err != nil {
+		t.Fatalf("logging in with the new password: %v", err)
+	}
+
+	err := m.ResetPassword(ctx, types.PasswordResetParams{Token: code, NewPassword: "another password"})
+	if !errors.Is(err, types.ErrInvalidUserToken) {
+		t.Fatalf("reusing the code: got %v, want %v", err, types.ErrInvalidUserToken)
+	}
+}
@@ -0,0 +1,164 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var ErrBookingNotInReservation = errors.New("booking is not a line of this reservation")
+
+// AddNewReservation books every room of the reservation or none of them: if
+// a line cannot be booked, the lines booked so far and the reservation are
+// deleted again, releasing their nights.
+func (m *Manager) AddNewReservation(ctx context.Context, params types.NewReservationParams) (*types.Reservation, error) {
+	// Make sure user ID in params exists
+	if _, err := m.UserStore.GetUserByID(ctx, params.UserID); err != nil {
+		return nil, err
+	}
+
+	reservation, err := m.ReservationStore.InsertReservation(ctx, types.NewReservationFromParams(params))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range params.Lines {
+		booking, err := m.bookRoom(ctx, params.BookingParams(line), reservation.ID)
+		if err != nil {
+			m.rollbackReservation(ctx, reservation)
+			return nil, err
+		}
+		reservation.BookingIDs = append(reservation.BookingIDs, booking.ID)
+	}
+
+	if err := m.ReservationStore.UpdateReservation(ctx, reservation); err != nil {
+		m.rollbackReservation(ctx, reservation)
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// rollbackReservation deletes a partially created reservation and its lines.
+// Failures are only logged since the caller already returns the original error.
+func (m *Manager) rollbackReservation(ctx context.Context, reservation *types.Reservation) {
+	for _, bookingID := range reservation.BookingIDs {
+		if err := m.BookingStore.DeleteBookingByID(ctx, bookingID); err != nil {
+			log.Printf("Error rolling back booking %s of reservation %s: %v\n", bookingID, reservation.ID, err)
+		}
+	}
+	if err := m.ReservationStore.DeleteReservationByID(ctx, reservation.ID); err != nil {
+		log.Printf("Error rolling back reservation %s: %v\n", reservation.ID, err)
+	}
+}
+
+// GetReservation returns the reservation together with its line bookings.
+func (m *Manager) GetReservation(ctx context.Context, reservationID string) (*types.Reservation, []*types.Booking, error) {
+	reservation, err := m.ReservationStore.GetReservationByID(ctx, reservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bookings := make([]*types.Booking, 0, len(reservation.BookingIDs))
+	for _, bookingID := range reservation.BookingIDs {
+		booking, err := m.BookingStore.GetBookingByID(ctx, bookingID)
+		if err != nil {
+			return nil, nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return reservation, bookings, nil
+}
+
+func (m *Manager) ListUserReservations(ctx context.Context, userID string) ([]*types.Reservation, error) {
+	return m.ReservationStore.GetReservationsByUserID(ctx, userID)
+}
+
+// CancelReservation cancels every line that can still be canceled.
+func (m *Manager) CancelReservation(ctx context.Context, reservationID string) (*types.Reservation, []*types.Booking, error) {
+	reservation, bookings, err := m.GetReservation(ctx, reservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if reservation.Status == types.ReservationCanceled {
+		return nil, nil, fmt.Errorf("%w: reservation is already canceled", ErrInvalidTransition)
+	}
+
+	for i, booking := range bookings {
+		if !canTransition(booking.BookingStatus, types.StatusCanceled) {
+			continue
+		}
+		canceled, err := m.cancelBooking(ctx, booking)
+		if err != nil {
+			return nil, nil, err
+		}
+		bookings[i] = canceled
+	}
+
+	if err := m.syncReservationStatus(ctx, reservation, bookings); err != nil {
+		return nil, nil, err
+	}
+
+	return reservation, bookings, nil
+}
+
+// CancelReservationLine cancels a single line of the reservation.
+func (m *Manager) CancelReservationLine(ctx context.Context, reservationID, bookingID string) (*types.Reservation, []*types.Booking, error) {
+	reservation, bookings, err := m.GetReservation(ctx, reservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	line := -1
+	for i, booking := range bookings {
+		if booking.ID == bookingID {
+			line = i
+		}
+	}
+	if line < 0 {
+		return nil, nil, ErrBookingNotInReservation
+	}
+
+	canceled, err := m.cancelBooking(ctx, bookings[line])
+	if err != nil {
+		return nil, nil, err
+	}
+	bookings[line] = canceled
+
+	if err := m.syncReservationStatus(ctx, reservation, bookings); err != nil {
+		return nil, nil, err
+	}
+
+	return reservation, bookings, nil
+}
+
+// syncReservation updates the status of the reservation after one of its
+// lines changed.
+func (m *Manager) syncReservation(ctx context.Context, reservationID string) error {
+	reservation, bookings, err := m.GetReservation(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	return m.syncReservationStatus(ctx, reservation, bookings)
+}
+
+// syncReservationStatus marks the reservation canceled once no line will be
+// stayed in, every one being canceled or expired.
+func (m *Manager) syncReservationStatus(ctx context.Context, reservation *types.Reservation, bookings []*types.Booking) error {
+	if reservation.Status == types.ReservationCanceled {
+		return nil
+	}
+	for _, booking := range bookings {
+		if booking.BookingStatus != types.StatusCanceled && booking.BookingStatus != types.StatusExpired {
+			return nil
+		}
+	}
+
+	reservation.Status = types.ReservationCanceled
+	return m.ReservationStore.UpdateReservation(ctx, reservation)
+}
@@ -0,0 +1,95 @@
+package business
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// addTestReservation books a reservation of the given number of rooms for two
+// nights, a week from now.
+func addTestReservation(t *testing.T, m *Manager, rooms int) *types.Reservation {
+	t.Helper()
+
+	var numbers []string
+	for i := 0; i < rooms; i++ {
+		numbers = append(numbers, strconv.Itoa(101+i))
+	}
+	_, roomIDs := addTestHotel(t, m, numbers...)
+
+	var lines []types.ReservationLine
+	for _, roomID := range roomIDs {
+		lines = append(lines, types.ReservationLine{RoomID: roomID, Adults: 1})
+	}
+
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	reservation, err := m.AddNewReservation(context.Background(), types.NewReservationParams{
+		UserID:   addTestUser(t, m, "ali@example.com"),
+		Lines:    lines,
+		FromDate: from,
+		TillDate: from.AddDate(0, 0, 2),
+		Guest:    types.GuestDetails{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Phone: "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reservation
+}
+
+func reservationStatus(t *testing.T, m *Manager, reservationID string) types.ReservationStatus {
+	t.Helper()
+
+	reservation, err := m.ReservationStore.GetReservationByID(context.Background(), reservationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reservation.Status
+}
+
+func TestCancelBookingOfLastLineCancelsReservation(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	reservation := addTestReservation(t, m, 2)
+
+	if _, err := m.CancelBooking(ctx, reservation.BookingIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got := reservationStatus(t, m, reservation.ID); got != types.ReservationActive {
+		t.Fatalf("with a line left: got %s, want %s", got, types.ReservationActive)
+	}
+
+	booking, err := m.CancelBooking(ctx, reservation.BookingIDs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if booking.BookingStatus != types.StatusCanceled || booking.Cancellation == nil {
+		t.Fatalf("got booking %s with cancellation %v, want it canceled with a charge", booking.BookingStatus, booking.Cancellation)
+	}
+	if got := reservationStatus(t, m, reservation.ID); got != types.ReservationCanceled {
+		t.Fatalf("got %s, want %s", got, types.ReservationCanceled)
+	}
+}
+
+func TestExpirePendingBookingsCancelsReservation(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	reservation := addTestReservation(t, m, 2)
+
+	if _, err := m.CancelBooking(ctx, reservation.BookingIDs[0]); err != nil {
+		t.Fatal(err)
+	}
+	// Every hold is stale with no time to confirm
+	expired, err := m.ExpirePendingBookings(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expired != 1 {
+		t.Fatalf("got %d expired, want 1", expired)
+	}
+	if got := reservationStatus(t, m, reservation.ID); got != types.ReservationCanceled {
+		t.Fatalf("got %s, want %s", got, types.ReservationCanceled)
+	}
+}
@@ -0,0 +1,198 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/auth"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshTokenTTL bounds a session; after it the user logs in again.
+const RefreshTokenTTL = time.Hour * 24 * 30
+
+// A refresh token is "<session ID>.<secret>"; only the hash of the whole token
+// is stored.
+const refreshTokenSeparator = "."
+
+// startSession logs the user in; mfa records that they gave a second factor.
+func (m *Manager) startSession(ctx context.Context, user *types.User, mfa bool) (*types.AuthTokens, error) {
+	refreshSecret, err := auth.NewRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := auth.NewRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	// The refresh token embeds the session ID, so the ID is fixed up front
+	session := &types.Session{
+		ID:            newSessionID(),
+		UserID:        user.ID.Hex(),
+		AccessTokenID: tokenID,
+		CreatedAt:     time.Now().UTC(),
+		ExpiresAt:     time.Now().UTC().Add(RefreshTokenTTL),
+		MFA:           mfa,
+	}
+	refreshToken := session.ID + refreshTokenSeparator + refreshSecret
+	session.RefreshTokenHash = auth.HashToken(refreshToken)
+
+	if _, err := m.SessionStore.InsertSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return m.issueTokens(user, session.ID, tokenID, refreshToken)
+}
+
+// RefreshSession trades a refresh token for a new access and refresh token
+// pair. The old pair stops working. A refresh token presented after it was
+// already traded in is taken as stolen, and the session is revoked.
+func (m *Manager) RefreshSession(ctx context.Context, refreshToken string) (*types.AuthTokens, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, refreshTokenSeparator)
+	if !ok {
+		return nil, types.ErrSessionRevoked
+	}
+
+	session, err := m.activeSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldHash := auth.HashToken(refreshToken)
+	if oldHash != session.RefreshTokenHash {
+		if err := m.SessionStore.RevokeSession(ctx, session.ID, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+		return nil, types.ErrSessionRevoked
+	}
+
+	// Reload the user so role changes apply from the next access token
+	user, err := m.UserStore.GetUserByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, types.ErrSessionRevoked
+		}
+		return nil, err
+	}
+
+	if err := user.EffectiveStatus().CanLogIn(); err != nil {
+		return nil, err
+	}
+
+	// A session from before the user's role required MFA logs in again
+	if !session.MFA {
+		required, err := m.mfaRequired(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, types.ErrMFARequired
+		}
+	}
+
+	refreshSecret, err := auth.NewRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	tokenID, err := auth.NewRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	newRefreshToken := session.ID + refreshTokenSeparator + refreshSecret
+
+	if err := m.SessionStore.RotateSession(ctx, session.ID, oldHash, auth.HashToken(newRefreshToken), tokenID); err != nil {
+		return nil, err
+	}
+
+	return m.issueTokens(user, session.ID, tokenID, newRefreshToken)
+}
+
+// Logout revokes the session, and with it its access and refresh tokens.
+func (m *Manager) Logout(ctx context.Context, sessionID string) error {
+	return m.SessionStore.RevokeSession(ctx, sessionID, time.Now().UTC())
+}
+
+// RevokeAllSessions logs the user out everywhere, as after a password change.
+func (m *Manager) RevokeAllSessions(ctx context.Context, userID string) error {
+	return m.SessionStore.RevokeUserSessions(ctx, userID, time.Now().UTC())
+}
+
+// AuthenticateAccessToken parses the access token and checks that its session
+// still honours it and its user may still log in.
+func (m *Manager) AuthenticateAccessToken(ctx context.Context, tokenString string) (*auth.Claims, error) {
+	claims, err := m.Tokens.ParseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.CheckAccessToken(ctx, claims.SessionID, claims.Id); err != nil {
+		return nil, err
+	}
+
+	user, err := m.UserStore.GetUserByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, types.ErrSessionRevoked
+		}
+		return nil, err
+	}
+	if err := user.EffectiveStatus().CanLogIn(); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// CheckAccessToken reports types.ErrSessionRevoked unless the session is
+// active and tokenID names its current access token.
+func (m *Manager) CheckAccessToken(ctx context.Context, sessionID string, tokenID string) error {
+	session, err := m.activeSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if tokenID == "" || tokenID != session.AccessTokenID {
+		return types.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (m *Manager) activeSession(ctx context.Context, sessionID string) (*types.Session, error) {
+	if !primitive.IsValidObjectID(sessionID) {
+		return nil, types.ErrSessionRevoked
+	}
+
+	session, err := m.SessionStore.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, types.ErrSessionRevoked
+		}
+		return nil, fmt.Errorf("getting session %s: %w", sessionID, err)
+	}
+
+	if !session.IsActive(time.Now()) {
+		return nil, types.ErrSessionRevoked
+	}
+	return session, nil
+}
+
+func newSessionID() string {
+	return primitive.NewObjectID().Hex()
+}
+
+func (m *Manager) issueTokens(user *types.User, sessionID, tokenID, refreshToken string) (*types.AuthTokens, error) {
+	accessToken, err := m.Tokens.GenerateAuthToken(user.ID.Hex(), string(user.EffectiveRole()), user.HotelIDs, sessionID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().UTC().Add(m.Tokens.AccessTokenTTL()),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// logInTestUser registers a user and logs them in.
+func logInTestUser(t *testing.T, m *Manager) *types.AuthTokens {
+	t.Helper()
+
+	addTestUser(t, m, "ali@example.com")
+	tokens, _, err := m.GetUserToken(context.Background(), AuthParams{Email: "ali@example.com", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+func TestRefreshSessionRevokesOnReuse(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	first := logInTestUser(t, m)
+
+	second, err := m.RefreshSession(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("got the same refresh token back, want a rotated one")
+	}
+	if _, err := m.AuthenticateAccessToken(ctx, first.AccessToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("access token replaced by the refresh: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+	if _, err := m.AuthenticateAccessToken(ctx, second.AccessToken); err != nil {
+		t.Fatalf("new access token: %v", err)
+	}
+
+	// Replaying the rotated token means it leaked, so the whole session ends
+	if _, err := m.RefreshSession(ctx, first.RefreshToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("replayed refresh token: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+	if _, err := m.RefreshSession(ctx, second.RefreshToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("new refresh token: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+	if _, err := m.AuthenticateAccessToken(ctx, second.AccessToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("new access token: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+}
@@ -3,41 +3,66 @@ package business
 import (
 	"context"
 	"errors"
+	"time"
 
-	"github.com/mkabdelrahman/hotel-reservation/auth"
 	"github.com/mkabdelrahman/hotel-reservation/types"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthParams struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// ClientIP is the address the attempt came from, for throttling
+	ClientIP string `json:"-"`
 }
 
-func (m *Manager) GetUserToken(ctx context.Context, authParams AuthParams) (string, error) {
+// GetUserToken logs the user in, starting a session with its own access and
+// refresh tokens. An unknown email and a wrong password both fail with
+// types.ErrInvalidCredentials, and repeated failures for the account or the
+// client IP are throttled with a *types.LoginThrottledError. Users with MFA,
+// or whose role requires it, get a challenge for CompleteMFALogin instead of
+// tokens.
+func (m *Manager) GetUserToken(ctx context.Context, authParams AuthParams) (*types.AuthTokens, *types.MFAChallenge, error) {
+	throttles := loginThrottles(authParams)
+	if err := m.checkLoginThrottles(ctx, throttles); err != nil {
+		return nil, nil, err
+	}
 
 	user, err := m.UserStore.GetUserByEmail(ctx, authParams.Email)
-	if err != nil {
-		return "", err
+	if err != nil && !errors.Is(err, types.ErrNotFound) {
+		return nil, nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.EncryptedPassword), []byte(authParams.Password)); err != nil {
-		return "", err
+	// Users from an identity provider may have no password to compare with
+	if user == nil || user.EncryptedPassword == "" {
+		compareDummyPassword(authParams.Password)
+	}
+	if user == nil || !isValidPassword(user, authParams.Password) {
+		if err := m.recordLoginFailure(ctx, throttles); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, types.ErrInvalidCredentials
 	}
 
-	ok, err := types.IsValidPassword(user.EncryptedPassword, authParams.Password)
-
-	if err != nil || !ok {
-		return "", err
+	if err := m.LoginAttemptStore.ResetLoginAttempts(ctx, accountLoginKey(authParams.Email)); err != nil {
+		return nil, nil, err
 	}
 
-	token, err := auth.GenerateAuthToken(user.ID.Hex())
+	if err := user.EffectiveStatus().CanLogIn(); err != nil {
+		return nil, nil, err
+	}
 
-	if err != nil {
-		return "", err
+	challenge, err := m.mfaChallenge(ctx, user)
+	if err != nil || challenge != nil {
+		return nil, challenge, err
 	}
 
-	return token, nil
+	tokens, err := m.startSession(ctx, user, false)
+	return tokens, nil, err
+}
+
+func isValidPassword(user *types.User, password string) bool {
+	ok, _ := types.IsValidPassword(user.EncryptedPassword, password)
+	return ok
 }
 
 func (m *Manager) AddNewUser(ctx context.Context, params types.NewUserParams) (string, error) {
@@ -65,6 +90,7 @@ func (m *Manager) AddNewAdmin(ctx context.Context, params types.NewUserParams) (
 	}
 
 	user.IsAdmin = true
+	user.Role = types.RoleAdmin
 
 	insertedUser, err := m.UserStore.InsertUser(ctx, user)
 	if err != nil {
@@ -78,15 +104,77 @@ func (m *Manager) AddNewAdmin(ctx context.Context, params types.NewUserParams) (
 	return insertedUser.ID.Hex(), nil
 }
 
-// func (m *Manager) ChangePassword(ctx context.Context, userID, newPassword string) error {
-// 	return nil
-// }
+// SetUserRole gives the user a role, scoped to the hotels for staff and hotel
+// managers. Sessions pick it up at their next refresh; access tokens already
+// issued keep the old role until they expire.
+func (m *Manager) SetUserRole(ctx context.Context, userID string, params types.SetUserRoleParams) (*types.User, error) {
+	for _, hotelID := range params.HotelIDs {
+		if _, err := m.HotelStore.GetHotel(ctx, hotelID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.UserStore.UpdateUserRole(ctx, userID, params.Role, params.HotelIDs); err != nil {
+		return nil, err
+	}
+	return m.UserStore.GetUserByID(ctx, userID)
+}
+
+// SetAccountStatus changes the status of the user's account. Any status but
+// active logs the user out everywhere. Suspending or deleting it also, if
+// asked, cancels the bookings they have not arrived for yet, which are
+// returned.
+func (m *Manager) SetAccountStatus(ctx context.Context, userID, changedBy string, params types.SetAccountStatusParams) (*types.User, []*types.Booking, error) {
+	change := types.AccountStatusChange{
+		Reason:    params.Reason,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now().UTC(),
+	}
+	if err := m.UserStore.UpdateUserStatus(ctx, userID, params.Status, change); err != nil {
+		return nil, nil, err
+	}
+
+	var canceled []*types.Booking
+	if params.Status.CanLogIn() != nil {
+		if err := m.RevokeAllSessions(ctx, userID); err != nil {
+			return nil, nil, err
+		}
+
+		if params.CancelFutureBookings {
+			var err error
+			if canceled, err = m.cancelFutureBookings(ctx, userID); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
 
-// func (m *Manager) SuspendUserAccount(ctx context.Context, userID string) error {
-// 	return nil
-// }
+	user, err := m.UserStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, canceled, nil
+}
 
-// func (m *Manager) ActivateUserAccount(ctx context.Context, userID string) error {
+// cancelFutureBookings cancels the user's pending and confirmed bookings that
+// start today or later.
+func (m *Manager) cancelFutureBookings(ctx context.Context, userID string) ([]*types.Booking, error) {
+	bookings, err := m.BookingStore.GetBookingsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-// 	return nil
-// }
+	today := types.StartOfDay(time.Now())
+	var canceled []*types.Booking
+	for _, booking := range bookings {
+		if !canTransition(booking.BookingStatus, types.StatusCanceled) || types.StartOfDay(booking.FromDate).Before(today) {
+			continue
+		}
+
+		booking, err := m.CancelBooking(ctx, booking.ID)
+		if err != nil {
+			return nil, err
+		}
+		canceled = append(canceled, booking)
+	}
+	return canceled, nil
+}
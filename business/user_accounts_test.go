@@ -0,0 +1,70 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestRegisterUserRejectsTakenEmail(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+
+	params := types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"}
+	if _, err := m.RegisterUser(ctx, params); err != nil {
+		t.Fatal(err)
+	}
+
+	params.Password = "another password"
+	if _, err := m.RegisterUser(ctx, params); !errors.Is(err, types.ErrEmailTaken) {
+		t.Fatalf("got %v, want %v", err, types.ErrEmailTaken)
+	}
+}
+
+func TestSetAccountStatusSuspends(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	tokens := logInTestUser(t, m)
+	user, err := m.UserStore.GetUserByEmail(ctx, "ali@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := user.ID.Hex()
+
+	_, roomIDs := addTestHotel(t, m, "101")
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	bookingID, err := m.AddNewBooking(ctx, types.NewBookingParams{UserID: userID, RoomID: roomIDs[0], FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suspend := types.SetAccountStatusParams{Status: types.AccountSuspended, Reason: "chargeback", CancelFutureBookings: true}
+	user, canceled, err := m.SetAccountStatus(ctx, userID, "admin", suspend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Status != types.AccountSuspended || user.StatusChange == nil || user.StatusChange.Reason != "chargeback" {
+		t.Fatalf("got status %s with change %+v, want it suspended with the reason", user.Status, user.StatusChange)
+	}
+	if len(canceled) != 1 || canceled[0].ID != bookingID || canceled[0].BookingStatus != types.StatusCanceled {
+		t.Fatalf("got %d canceled bookings, want the future booking", len(canceled))
+	}
+
+	login := AuthParams{Email: "ali@example.com", Password: "password"}
+	if _, _, err := m.GetUserToken(ctx, login); !errors.Is(err, types.ErrAccountSuspended) {
+		t.Fatalf("logging in: got %v, want %v", err, types.ErrAccountSuspended)
+	}
+	if _, err := m.AuthenticateAccessToken(ctx, tokens.AccessToken); !errors.Is(err, types.ErrSessionRevoked) {
+		t.Fatalf("access token: got %v, want %v", err, types.ErrSessionRevoked)
+	}
+
+	if _, _, err := m.SetAccountStatus(ctx, userID, "admin", types.SetAccountStatusParams{Status: types.AccountActive}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := m.GetUserToken(ctx, login); err != nil {
+		t.Fatalf("logging in once active again: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestListUsersPaginates(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	for _, name := range []string{"c", "a", "e", "b", "d"} {
+		addTestUser(t, m, name+"@example.com")
+	}
+
+	tests := []struct {
+		name   string
+		filter types.UsersPaginationFilter
+		want   []string
+	}{
+		{"first page", types.UsersPaginationFilter{Page: 1, PageSize: 2, SortBy: "email", SortDir: types.AscSort}, []string{"a@example.com", "b@example.com"}},
+		{"descending", types.UsersPaginationFilter{Page: 2, PageSize: 2, SortBy: "email", SortDir: types.DescSort}, []string{"c@example.com", "b@example.com"}},
+		{"short last page", types.UsersPaginationFilter{Page: 3, PageSize: 2, SortBy: "email", SortDir: types.AscSort}, []string{"e@example.com"}},
+		{"past the end", types.UsersPaginationFilter{Page: 4, PageSize: 2, SortBy: "email", SortDir: types.AscSort}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := m.ListUsers(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, user := range users {
+				got = append(got, user.Email)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := m.ListUsers(ctx, types.UsersPaginationFilter{Page: 0, PageSize: 2, SortDir: types.AscSort}); err == nil {
+		t.Fatal("got no error for page 0")
+	}
+}
+
+func TestGetUserByID(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t)
+	userID := addTestUser(t, m, "ali@example.com")
+
+	user, err := m.GetUserByID(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The store hands out copies, like documents decoded from MongoDB
+	user.FirstName = "changed"
+	if user, err = m.GetUserByID(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if user.FirstName != "Ali" {
+		t.Fatalf("got first name %q, want the stored user unchanged", user.FirstName)
+	}
+
+	if _, err := m.GetUserByID(ctx, primitive.NewObjectID().Hex()); !errors.Is(err, types.ErrNotFound) {
+		t.Fatalf("unknown user: got %v, want %v", err, types.ErrNotFound)
+	}
+}
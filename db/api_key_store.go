@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyStore interface {
+	InsertAPIKey(ctx context.Context, key *types.APIKey) (*types.APIKey, error)
+
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.APIKey, error)
+
+	// GetAPIKeys returns the keys of the user, or every key if userID is
+	// empty, oldest first.
+	GetAPIKeys(ctx context.Context, userID string) ([]*types.APIKey, error)
+
+	TouchAPIKey(ctx context.Context, keyID string, at time.Time) error
+
+	RevokeAPIKey(ctx context.Context, keyID string, at time.Time) error
+}
+
+type MongoAPIKeyStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoAPIKeyStore(client *mongo.Client, dbName string, collName string) *MongoAPIKeyStore {
+
+	return &MongoAPIKeyStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoAPIKeyStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoAPIKeyStore) InsertAPIKey(ctx context.Context, key *types.APIKey) (*types.APIKey, error) {
+	result, err := s.coll.InsertOne(ctx, key)
+	if err != nil {
+		log.Printf("Error inserting API key: %v\n", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("could not convert InsertedID to ObjectID")
+	}
+	key.ID = insertedID.Hex()
+	return key, nil
+}
+
+func (s *MongoAPIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.APIKey, error) {
+	var key types.APIKey
+	err := s.coll.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *MongoAPIKeyStore) GetAPIKeys(ctx context.Context, userID string) ([]*types.APIKey, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("Error getting API keys: %v\n", err)
+		return nil, err
+	}
+
+	keys := []*types.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		log.Printf("Error decoding API keys: %v\n", err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *MongoAPIKeyStore) TouchAPIKey(ctx context.Context, ID string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.coll.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"last_used_at": at}})
+	if err != nil {
+		log.Printf("Error touching API key: %v\n", err)
+	}
+	return err
+}
+
+func (s *MongoAPIKeyStore) RevokeAPIKey(ctx context.Context, ID string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		log.Printf("Error revoking API key: %v\n", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
@@ -13,16 +13,27 @@ import (
 )
 
 type BookingStore interface {
+	// InsertBooking atomically checks and holds the booking's nights. It fails
+	// with types.ErrRoomUnavailable if an active booking already holds any of them.
 	InsertBooking(ctx context.Context, hotel *types.Booking) (*types.Booking, error)
 
 	GetBookingByID(ctx context.Context, bookingID string) (*types.Booking, error)
 
 	GetBookingsByUserID(ctx context.Context, userID string) ([]*types.Booking, error)
 
-	GetBookingByRoomAndTimeRange(ctx context.Context, roomID string, fromDate, tillDate time.Time) (*types.Booking, error)
+	// GetActiveBookingsByRoomAndTimeRange returns every booking of the room that
+	// overlaps [fromDate, tillDate) and still holds it. Canceled bookings are excluded.
+	GetActiveBookingsByRoomAndTimeRange(ctx context.Context, roomID string, fromDate, tillDate time.Time) ([]*types.Booking, error)
+
+	// GetActiveBookingsByRoomID returns every booking that still holds the room.
+	GetActiveBookingsByRoomID(ctx context.Context, roomID string) ([]*types.Booking, error)
 
 	GetBookings(ctx context.Context) ([]*types.Booking, error)
 
+	GetBookingsByStatusCreatedBefore(ctx context.Context, status types.BookingStatus, before time.Time) ([]*types.Booking, error)
+
+	// UpdateBooking keeps the held nights in sync with the booking's room, dates
+	// and status, failing with types.ErrRoomUnavailable like InsertBooking.
 	UpdateBooking(ctx context.Context, booking *types.Booking) error
 
 	DeleteBookingByID(ctx context.Context, bookigID string) error
@@ -30,11 +41,22 @@ type BookingStore interface {
 	// QueryBooking(ctx context.Context, criteria types.BookingQueryCriteria) ([]*types.Booking, error)
 }
 
+// MongoBookingStore prevents double bookings with one slot document per room
+// and night, kept in a companion collection. The slot _id is derived from the
+// room and the night, so the unique _id index lets exactly one booking hold it.
 type MongoBookingStore struct {
 	client   *mongo.Client
 	collName string
 	dbName   string
 	coll     *mongo.Collection
+	slots    *mongo.Collection
+}
+
+type bookingSlot struct {
+	ID        string    `bson:"_id"`
+	RoomID    string    `bson:"room_id"`
+	Night     time.Time `bson:"night"`
+	BookingID string    `bson:"booking_id"`
 }
 
 func NewMongoBookingStore(client *mongo.Client, dbName string, collName string) *MongoBookingStore {
@@ -44,24 +66,43 @@ func NewMongoBookingStore(client *mongo.Client, dbName string, collName string)
 		collName: collName,
 		dbName:   dbName,
 		coll:     client.Database(dbName).Collection(collName),
+		slots:    client.Database(dbName).Collection(slotsCollName(collName)),
 	}
 }
 
+// slotsCollName returns the name of the slot collection of a booking collection.
+func slotsCollName(bookingCollName string) string {
+	return bookingCollName + "_slots"
+}
+
 func (m *MongoBookingStore) InsertBooking(ctx context.Context, booking *types.Booking) (*types.Booking, error) {
-	result, err := m.coll.InsertOne(ctx, booking)
+	oid := primitive.NewObjectID()
+
+	if booking.BookingStatus.IsActive() {
+		if _, err := m.reserveSlots(ctx, oid.Hex(), booking); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := bsonWithID(booking, oid)
 	if err != nil {
-		log.Printf("Error inserting booking: %v\n", err)
 		return nil, err
 	}
-	insertedID, ok := result.InsertedID.(primitive.ObjectID)
-	if !ok {
-		return nil, errors.New("could not convert InsertedID to ObjectID")
+
+	_, err = m.coll.InsertOne(ctx, doc)
+	if err != nil {
+		log.Printf("Error inserting booking: %v\n", err)
+		m.releaseSlots(ctx, oid.Hex(), nil)
+		return nil, err
 	}
-	booking.ID = insertedID.Hex()
+	booking.ID = oid.Hex()
 	return booking, nil
 }
 
 func (s *MongoBookingStore) Drop(c context.Context) error {
+	if err := s.slots.Drop(c); err != nil {
+		return err
+	}
 	return s.coll.Drop(c)
 }
 
@@ -76,7 +117,7 @@ func (s *MongoBookingStore) GetBookingByID(ctx context.Context, ID string) (*typ
 
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("id not found")
+			return nil, types.ErrNotFound
 		}
 		return nil, err
 	}
@@ -111,26 +152,48 @@ func (s *MongoBookingStore) GetBookingsByUserID(ctx context.Context, userID stri
 	return bookings, nil
 }
 
-func (m *MongoBookingStore) GetBookingByRoomAndTimeRange(ctx context.Context, roomID string, fromDate, tillDate time.Time) (*types.Booking, error) {
-	// Check if the room is booked for the specified time range
+func (m *MongoBookingStore) GetActiveBookingsByRoomAndTimeRange(ctx context.Context, roomID string, fromDate, tillDate time.Time) ([]*types.Booking, error) {
 	filter := bson.M{
-		"room_id":   roomID,
-		"from_date": bson.M{"$lt": tillDate},
-		"till_date": bson.M{"$gt": fromDate},
+		"room_id":        roomID,
+		"from_date":      bson.M{"$lt": tillDate},
+		"till_date":      bson.M{"$gt": fromDate},
+		"booking_status": bson.M{"$in": types.ActiveBookingStatuses},
 	}
 
-	var existingBooking types.Booking
-	err := m.coll.FindOne(ctx, filter).Decode(&existingBooking)
-	if err == mongo.ErrNoDocuments {
-		// No booking found for the specified time range, the room is available
-		return nil, nil
-	} else if err != nil {
-		// Handle other errors
+	cursor, err := m.coll.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Error getting bookings by room and time range: %v\n", err)
 		return nil, err
 	}
 
-	// A booking exists for the specified time range, indicating the room is already booked
-	return &existingBooking, nil
+	var bookings []*types.Booking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		log.Printf("Error decoding bookings: %v\n", err)
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (m *MongoBookingStore) GetActiveBookingsByRoomID(ctx context.Context, roomID string) ([]*types.Booking, error) {
+	filter := bson.M{
+		"room_id":        roomID,
+		"booking_status": bson.M{"$in": types.ActiveBookingStatuses},
+	}
+
+	cursor, err := m.coll.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Error getting bookings by room: %v\n", err)
+		return nil, err
+	}
+
+	var bookings []*types.Booking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		log.Printf("Error decoding bookings: %v\n", err)
+		return nil, err
+	}
+
+	return bookings, nil
 }
 
 func (m *MongoBookingStore) GetBookings(ctx context.Context) ([]*types.Booking, error) {
@@ -159,12 +222,42 @@ func (m *MongoBookingStore) GetBookings(ctx context.Context) ([]*types.Booking,
 	return bookings, nil
 }
 
+func (m *MongoBookingStore) GetBookingsByStatusCreatedBefore(ctx context.Context, status types.BookingStatus, before time.Time) ([]*types.Booking, error) {
+	filter := bson.M{
+		"booking_status": status,
+		"created_at":     bson.M{"$lt": before},
+	}
+
+	cursor, err := m.coll.Find(ctx, filter)
+	if err != nil {
+		log.Printf("Error getting bookings by status: %v\n", err)
+		return nil, err
+	}
+
+	var bookings []*types.Booking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		log.Printf("Error decoding bookings: %v\n", err)
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
 func (m *MongoBookingStore) UpdateBooking(ctx context.Context, booking *types.Booking) error {
 	oid, err := primitive.ObjectIDFromHex(booking.ID)
 	if err != nil {
 		return err
 	}
 
+	var keep, reserved []string
+	if booking.BookingStatus.IsActive() {
+		reserved, err = m.reserveSlots(ctx, booking.ID, booking)
+		if err != nil {
+			return err
+		}
+		keep = slotIDs(booking)
+	}
+
 	filter := bson.M{"_id": oid}
 
 	update := bson.M{
@@ -174,19 +267,35 @@ func (m *MongoBookingStore) UpdateBooking(ctx context.Context, booking *types.Bo
 			"from_date":      booking.FromDate,
 			"till_date":      booking.TillDate,
 			"booking_status": booking.BookingStatus,
+			"total_price":    booking.TotalPrice,
+			"currency":       booking.Currency,
+			"nights":         booking.Nights,
+			"cancellation":   booking.Cancellation,
+			"modifications":  booking.Modifications,
+			"confirmed_at":   booking.ConfirmedAt,
+			"checked_in_at":  booking.CheckedInAt,
+			"checked_out_at": booking.CheckedOutAt,
+			"no_show_at":     booking.NoShowAt,
+			"canceled_at":    booking.CanceledAt,
+			"expired_at":     booking.ExpiredAt,
 		},
 	}
 
 	result, err := m.coll.UpdateOne(ctx, filter, update)
 	if err != nil {
+		log.Printf("Error updating booking: %v\n", err)
+		// The booking keeps its old nights, so give back the new ones
+		m.unreserveSlots(ctx, booking.ID, reserved)
 		return err
 	}
 
-	if result.ModifiedCount == 0 {
+	if result.MatchedCount == 0 {
+		m.releaseSlots(ctx, booking.ID, nil)
 		return errors.New("no matching booking found for update")
 	}
 
-	return nil
+	// Release the nights the booking no longer holds
+	return m.releaseSlots(ctx, booking.ID, keep)
 }
 
 func (s *MongoBookingStore) DeleteBookingByID(ctx context.Context, ID string) error {
@@ -200,5 +309,130 @@ func (s *MongoBookingStore) DeleteBookingByID(ctx context.Context, ID string) er
 		return err
 	}
 
+	return s.releaseSlots(ctx, ID, nil)
+}
+
+// reserveSlots holds every night of the booking for bookingID and returns the
+// IDs of the slots it inserted. Nights the booking already holds are kept; if
+// another booking holds any of the others, the slots inserted by this call are
+// removed again and types.ErrRoomUnavailable is returned.
+func (m *MongoBookingStore) reserveSlots(ctx context.Context, bookingID string, booking *types.Booking) ([]string, error) {
+	held := map[string]bool{}
+	cursor, err := m.slots.Find(ctx, bson.M{"booking_id": bookingID})
+	if err != nil {
+		return nil, err
+	}
+	var heldSlots []bookingSlot
+	if err := cursor.All(ctx, &heldSlots); err != nil {
+		return nil, err
+	}
+	for _, slot := range heldSlots {
+		held[slot.ID] = true
+	}
+
+	var inserted []string
+	for _, night := range bookingNights(booking) {
+		id := slotID(booking.RoomID, night)
+		if held[id] {
+			continue
+		}
+
+		_, err := m.slots.InsertOne(ctx, bookingSlot{
+			ID:        id,
+			RoomID:    booking.RoomID,
+			Night:     night,
+			BookingID: bookingID,
+		})
+		if err != nil {
+			m.unreserveSlots(ctx, bookingID, inserted)
+			if mongo.IsDuplicateKeyError(err) {
+				return nil, types.ErrRoomUnavailable
+			}
+			return nil, err
+		}
+		inserted = append(inserted, id)
+	}
+
+	return inserted, nil
+}
+
+// unreserveSlots frees the given slots if bookingID holds them. It is used to
+// roll back reserveSlots, so errors are only logged.
+func (m *MongoBookingStore) unreserveSlots(ctx context.Context, bookingID string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	if _, err := m.slots.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "booking_id": bookingID}); err != nil {
+		log.Printf("Error releasing booking slots: %v\n", err)
+	}
+}
+
+// CountMissingSlots returns how many nights of an active booking it does not
+// hold, as for bookings stored before nights were held in slots. UpdateBooking
+// holds them again.
+func (m *MongoBookingStore) CountMissingSlots(ctx context.Context, booking *types.Booking) (int, error) {
+	if !booking.BookingStatus.IsActive() {
+		return 0, nil
+	}
+
+	ids := slotIDs(booking)
+	held, err := m.slots.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}, "booking_id": booking.ID})
+	if err != nil {
+		log.Printf("Error counting booking slots: %v\n", err)
+		return 0, err
+	}
+	return len(ids) - int(held), nil
+}
+
+// releaseSlots frees the nights held by bookingID, except those in keep.
+func (m *MongoBookingStore) releaseSlots(ctx context.Context, bookingID string, keep []string) error {
+	filter := bson.M{"booking_id": bookingID}
+	if len(keep) > 0 {
+		filter["_id"] = bson.M{"$nin": keep}
+	}
+
+	_, err := m.slots.DeleteMany(ctx, filter)
+	if err != nil {
+		log.Printf("Error releasing booking slots: %v\n", err)
+		return err
+	}
 	return nil
 }
+
+func bookingNights(booking *types.Booking) []time.Time {
+	return types.Nights(booking.FromDate, booking.TillDate)
+}
+
+func slotID(roomID string, night time.Time) string {
+	return roomID + "/" + night.Format(types.DateLayout)
+}
+
+func slotIDs(booking *types.Booking) []string {
+	var ids []string
+	for _, night := range bookingNights(booking) {
+		ids = append(ids, slotID(booking.RoomID, night))
+	}
+	return ids
+}
+
+// bsonWithID encodes v as a document whose _id is the given ObjectID, for
+// types that keep their ID as a hex string.
+func bsonWithID(v interface{}, oid primitive.ObjectID) (bson.D, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	fields := bson.D{{Key: "_id", Value: oid}}
+	for _, field := range doc {
+		if field.Key != "_id" {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
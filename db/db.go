@@ -1,2 +1,19 @@
 package db
 
+// Stores are the datastores the business layer works with.
+type Stores struct {
+	HotelStore   HotelStore
+	RoomStore    RoomStore
+	UserStore    UserStore
+	BookingStore BookingStore
+
+	ReservationStore ReservationStore
+	SessionStore     SessionStore
+	UserTokenStore   UserTokenStore
+	OutboxStore      OutboxStore
+
+	LoginAttemptStore LoginAttemptStore
+	SettingsStore     SettingsStore
+	APIKeyStore       APIKeyStore
+	OIDCLoginStore    OIDCLoginStore
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"log"
+	"regexp"
 
+	"github.com/mkabdelrahman/hotel-reservation/pricing"
 	"github.com/mkabdelrahman/hotel-reservation/types"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,23 +24,31 @@ type HotelStore interface {
 
 	GetHotels(ctx context.Context) ([]*types.Hotel, error)
 
-	QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.Hotel, error)
+	// QueryHotels returns the hotels matching the criteria that have at least
+	// one matching room, free for the whole stay when dates are given.
+	QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.HotelSearchResult, error)
 }
 
+// MongoHotelStore answers hotel searches from the rooms collection and the
+// booking slots, so it is given the names of those collections too.
 type MongoHotelStore struct {
 	client   *mongo.Client
 	collName string
 	dbName   string
 	coll     *mongo.Collection
+	rooms    *mongo.Collection
+	slots    *mongo.Collection
 }
 
-func NewMongoHotelStore(client *mongo.Client, dbName string, collName string) *MongoHotelStore {
+func NewMongoHotelStore(client *mongo.Client, dbName string, collName string, roomCollName string, bookingCollName string) *MongoHotelStore {
 
 	return &MongoHotelStore{
 		client:   client,
 		collName: collName,
 		dbName:   dbName,
 		coll:     client.Database(dbName).Collection(collName),
+		rooms:    client.Database(dbName).Collection(roomCollName),
+		slots:    client.Database(dbName).Collection(slotsCollName(bookingCollName)),
 	}
 }
 
@@ -91,8 +101,9 @@ func (m *MongoHotelStore) UpdateHotel(ctx context.Context, hotel *types.Hotel) e
 	update := bson.M{"$set": bson.M{
 		"name":     hotel.Name,
 		"location": hotel.Location,
-		"rooms":    hotel.Rooms,
+		"rating":   hotel.Rating,
 		// Add other fields as needed
+		"cancellation_policy": hotel.CancellationPolicy,
 	}}
 
 	_, err = m.coll.UpdateOne(ctx, filter, update)
@@ -146,32 +157,142 @@ func (m *MongoHotelStore) GetHotels(ctx context.Context) ([]*types.Hotel, error)
 }
 
 func convertToMongoFilter(criteria types.QueryCriteria) bson.M {
-	filter := bson.M{"rating": criteria.Rating}
+	filter := bson.M{}
+	if criteria.Rating != 0 {
+		filter["rating"] = criteria.Rating
+	}
+	if criteria.Location != "" {
+		filter["location"] = bson.M{"$regex": "^" + regexp.QuoteMeta(criteria.Location) + "$", "$options": "i"}
+	}
+
+	return filter
+}
+
+// convertToMongoRoomFilter matches the rooms of the hotels by type, and by
+// price when no dates are searched. Capacity depends on the room type when no
+// max occupancy is set, so it is checked with criteria.MatchesRoom after
+// decoding; the price of a stay depends on the room's rates for its nights, so
+// it is checked after quoting.
+func convertToMongoRoomFilter(criteria types.QueryCriteria, hotelIDs []string) bson.M {
+	filter := bson.M{"hotel_id": bson.M{"$in": hotelIDs}}
+	if criteria.RoomType != 0 {
+		filter["type"] = criteria.RoomType
+	}
+	if criteria.HasDates() {
+		return filter
+	}
+
+	price := bson.M{}
+	if criteria.MinPrice > 0 {
+		price["$gte"] = criteria.MinPrice
+	}
+	if criteria.MaxPrice > 0 {
+		price["$lte"] = criteria.MaxPrice
+	}
+	if len(price) > 0 {
+		filter["price"] = price
+	}
 
 	return filter
 }
 
-func (s *MongoHotelStore) QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.Hotel, error) {
+func (s *MongoHotelStore) QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.HotelSearchResult, error) {
 	// Convert the QueryCriteria to a MongoDB filter
 	filter := convertToMongoFilter(criteria)
 
 	// Use the filter to query MongoDB
 	cursor, err := s.coll.Find(ctx, filter)
 	if err != nil {
+		log.Printf("Error querying hotels: %v\n", err)
 		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	// Iterate through the cursor and populate the result
 	var hotels []*types.Hotel
-	for cursor.Next(ctx) {
-		var hotel types.Hotel
-		err := cursor.Decode(&hotel)
+	if err := cursor.All(ctx, &hotels); err != nil {
+		log.Printf("Error decoding hotels: %v\n", err)
+		return nil, err
+	}
+	if len(hotels) == 0 {
+		return []*types.HotelSearchResult{}, nil
+	}
+
+	hotelIDs := make([]string, 0, len(hotels))
+	for _, hotel := range hotels {
+		hotelIDs = append(hotelIDs, hotel.ID)
+	}
+
+	cursor, err = s.rooms.Find(ctx, convertToMongoRoomFilter(criteria, hotelIDs))
+	if err != nil {
+		log.Printf("Error querying rooms: %v\n", err)
+		return nil, err
+	}
+
+	var rooms []*types.Room
+	if err := cursor.All(ctx, &rooms); err != nil {
+		log.Printf("Error decoding rooms: %v\n", err)
+		return nil, err
+	}
+
+	booked := map[string]bool{}
+	if criteria.HasDates() && len(rooms) > 0 {
+		roomIDs := make([]string, 0, len(rooms))
+		for _, room := range rooms {
+			roomIDs = append(roomIDs, room.ID)
+		}
+
+		// A room is taken if any night of the stay is held by a booking
+		bookedIDs, err := s.slots.Distinct(ctx, "room_id", bson.M{
+			"room_id": bson.M{"$in": roomIDs},
+			"night":   bson.M{"$gte": types.StartOfDay(criteria.From), "$lt": types.StartOfDay(criteria.Till)},
+		})
 		if err != nil {
+			log.Printf("Error querying booked rooms: %v\n", err)
 			return nil, err
 		}
-		hotels = append(hotels, &hotel)
+		for _, id := range bookedIDs {
+			if roomID, ok := id.(string); ok {
+				booked[roomID] = true
+			}
+		}
 	}
 
-	return hotels, nil
+	return SearchResults(criteria, hotels, rooms, booked), nil
+}
+
+// SearchResults pairs the hotels, in order, with their rooms that match the
+// criteria and are not booked, leaving out hotels without such a room. For a
+// search with dates, rooms are priced by their quote for the stay.
+func SearchResults(criteria types.QueryCriteria, hotels []*types.Hotel, rooms []*types.Room, booked map[string]bool) []*types.HotelSearchResult {
+	byHotel := make(map[string]*types.HotelSearchResult, len(hotels))
+	for _, hotel := range hotels {
+		byHotel[hotel.ID] = &types.HotelSearchResult{Hotel: hotel}
+	}
+
+	for _, room := range rooms {
+		result, ok := byHotel[room.HotelID]
+		if !ok || booked[room.ID] || !criteria.MatchesRoom(room) {
+			continue
+		}
+
+		price, total := room.Price, 0.0
+		if criteria.HasDates() {
+			quote, err := pricing.QuoteStay(room, criteria.From, criteria.Till)
+			if err != nil {
+				continue
+			}
+			price, total = quote.AverageNight(), quote.Total
+		}
+		if !criteria.MatchesPrice(price) {
+			continue
+		}
+		result.AddRoom(room, price, total)
+	}
+
+	results := []*types.HotelSearchResult{}
+	for _, hotel := range hotels {
+		if result := byHotel[hotel.ID]; result.AvailableRooms > 0 {
+			results = append(results, result)
+		}
+	}
+	return results
 }
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoginAttemptStore interface {
+	GetLoginAttempts(ctx context.Context, key string) (*types.LoginAttempts, error)
+
+	// RecordLoginFailure counts one more failure for the key and returns the
+	// new count.
+	RecordLoginFailure(ctx context.Context, key string, at time.Time) (*types.LoginAttempts, error)
+
+	LockLogin(ctx context.Context, key string, until time.Time) error
+
+	// ResetLoginAttempts forgets the failures of the key and unlocks it.
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+type MongoLoginAttemptStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoLoginAttemptStore(client *mongo.Client, dbName string, collName string) *MongoLoginAttemptStore {
+
+	return &MongoLoginAttemptStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoLoginAttemptStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoLoginAttemptStore) GetLoginAttempts(ctx context.Context, key string) (*types.LoginAttempts, error) {
+	var attempts types.LoginAttempts
+	err := s.coll.FindOne(ctx, bson.M{"_id": key}).Decode(&attempts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrNotFound
+		}
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+func (s *MongoLoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, at time.Time) (*types.LoginAttempts, error) {
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"last_failure_at": at},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempts types.LoginAttempts
+	err := s.coll.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&attempts)
+	if err != nil {
+		log.Printf("Error recording login failure: %v\n", err)
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+func (s *MongoLoginAttemptStore) LockLogin(ctx context.Context, key string, until time.Time) error {
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"locked_until": until}})
+	if err != nil {
+		log.Printf("Error locking login: %v\n", err)
+	}
+	return err
+}
+
+func (s *MongoLoginAttemptStore) ResetLoginAttempts(ctx context.Context, key string) error {
+	_, err := s.coll.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.APIKeyStore = (*APIKeyStore)(nil)
+
+// APIKeyStore is a thread-safe, in-memory implementation of db.APIKeyStore.
+type APIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]types.APIKey
+}
+
+func NewAPIKeyStore() *APIKeyStore {
+	return &APIKeyStore{
+		keys: make(map[string]types.APIKey),
+	}
+}
+
+func (s *APIKeyStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = make(map[string]types.APIKey)
+	return nil
+}
+
+func (s *APIKeyStore) InsertAPIKey(ctx context.Context, key *types.APIKey) (*types.APIKey, error) {
+	id, err := newID(key.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[id]; ok {
+		return nil, errDuplicateKey
+	}
+	key.ID = id
+	s.keys[id] = copyAPIKey(*key)
+	return key, nil
+}
+
+func (s *APIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*types.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.keys {
+		if key.KeyHash == keyHash {
+			key = copyAPIKey(key)
+			return &key, nil
+		}
+	}
+	return nil, types.ErrNotFound
+}
+
+func (s *APIKeyStore) GetAPIKeys(ctx context.Context, userID string) ([]*types.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := []*types.APIKey{}
+	for _, key := range s.keys {
+		if userID == "" || key.UserID == userID {
+			key := copyAPIKey(key)
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessID(keys[i].ID, keys[j].ID)
+	})
+	return keys, nil
+}
+
+func (s *APIKeyStore) TouchAPIKey(ctx context.Context, keyID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[keyID]; ok {
+		key.LastUsedAt = &at
+		s.keys[keyID] = key
+	}
+	return nil
+}
+
+func (s *APIKeyStore) RevokeAPIKey(ctx context.Context, keyID string, at time.Time) error {
+	if _, err := primitive.ObjectIDFromHex(keyID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[keyID]
+	if !ok {
+		return types.ErrNotFound
+	}
+	key.RevokedAt = &at
+	s.keys[keyID] = key
+	return nil
+}
+
+func copyAPIKey(k types.APIKey) types.APIKey {
+	k.Scopes = append([]types.Permission(nil), k.Scopes...)
+	for _, t := range []**time.Time{&k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt} {
+		if *t != nil {
+			at := **t
+			*t = &at
+		}
+	}
+	return k
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.BookingStore = (*BookingStore)(nil)
+
+// BookingStore is a thread-safe, in-memory implementation of db.BookingStore.
+type BookingStore struct {
+	mu       sync.RWMutex
+	bookings map[string]types.Booking
+}
+
+func NewBookingStore() *BookingStore {
+	return &BookingStore{
+		bookings: make(map[string]types.Booking),
+	}
+}
+
+func (s *BookingStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bookings = make(map[string]types.Booking)
+	return nil
+}
+
+func (s *BookingStore) InsertBooking(ctx context.Context, booking *types.Booking) (*types.Booking, error) {
+	id, err := newID(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bookings[id]; ok {
+		return nil, errDuplicateKey
+	}
+	if s.conflicts(booking) {
+		return nil, types.ErrRoomUnavailable
+	}
+	booking.ID = id
+	s.bookings[id] = *booking
+	return booking, nil
+}
+
+func (s *BookingStore) GetBookingByID(ctx context.Context, bookingID string) (*types.Booking, error) {
+	if _, err := primitive.ObjectIDFromHex(bookingID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	booking, ok := s.bookings[bookingID]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return &booking, nil
+}
+
+func (s *BookingStore) GetBookingsByUserID(ctx context.Context, userID string) ([]*types.Booking, error) {
+	return s.filter(func(b *types.Booking) bool {
+		return b.UserID == userID
+	}), nil
+}
+
+func (s *BookingStore) GetActiveBookingsByRoomAndTimeRange(ctx context.Context, roomID string, fromDate, tillDate time.Time) ([]*types.Booking, error) {
+	return s.filter(func(b *types.Booking) bool {
+		return b.RoomID == roomID && b.BookingStatus.IsActive() && b.Overlaps(fromDate, tillDate)
+	}), nil
+}
+
+func (s *BookingStore) GetActiveBookingsByRoomID(ctx context.Context, roomID string) ([]*types.Booking, error) {
+	return s.filter(func(b *types.Booking) bool {
+		return b.RoomID == roomID && b.BookingStatus.IsActive()
+	}), nil
+}
+
+func (s *BookingStore) GetBookings(ctx context.Context) ([]*types.Booking, error) {
+	return s.filter(func(*types.Booking) bool { return true }), nil
+}
+
+func (s *BookingStore) GetBookingsByStatusCreatedBefore(ctx context.Context, status types.BookingStatus, before time.Time) ([]*types.Booking, error) {
+	return s.filter(func(b *types.Booking) bool {
+		return b.BookingStatus == status && b.CreatedAt.Before(before)
+	}), nil
+}
+
+func (s *BookingStore) UpdateBooking(ctx context.Context, booking *types.Booking) error {
+	if _, err := primitive.ObjectIDFromHex(booking.ID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.bookings[booking.ID]; !ok {
+		return types.ErrNotFound
+	}
+	if s.conflicts(booking) {
+		return types.ErrRoomUnavailable
+	}
+	s.bookings[booking.ID] = *booking
+	return nil
+}
+
+func (s *BookingStore) DeleteBookingByID(ctx context.Context, bookingID string) error {
+	if _, err := primitive.ObjectIDFromHex(bookingID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.bookings, bookingID)
+	return nil
+}
+
+// conflicts reports whether another active booking holds any night of an
+// active booking. The caller must hold s.mu.
+func (s *BookingStore) conflicts(booking *types.Booking) bool {
+	if !booking.BookingStatus.IsActive() {
+		return false
+	}
+	for id, b := range s.bookings {
+		if id != booking.ID && b.RoomID == booking.RoomID && b.BookingStatus.IsActive() && b.Overlaps(booking.FromDate, booking.TillDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns copies of the bookings matching keep, in insertion order.
+func (s *BookingStore) filter(keep func(*types.Booking) bool) []*types.Booking {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var bookings []*types.Booking
+	for _, b := range s.bookings {
+		b := b
+		if keep(&b) {
+			bookings = append(bookings, &b)
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool {
+		return lessID(bookings[i].ID, bookings[j].ID)
+	})
+	return bookings
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.HotelStore = (*HotelStore)(nil)
+
+// HotelStore is a thread-safe, in-memory implementation of db.HotelStore.
+// Searches look up rooms and bookings in the given stores.
+type HotelStore struct {
+	mu     sync.RWMutex
+	hotels map[string]types.Hotel
+
+	rooms    *RoomStore
+	bookings *BookingStore
+}
+
+func NewHotelStore(rooms *RoomStore, bookings *BookingStore) *HotelStore {
+	return &HotelStore{
+		hotels:   make(map[string]types.Hotel),
+		rooms:    rooms,
+		bookings: bookings,
+	}
+}
+
+func (s *HotelStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hotels = make(map[string]types.Hotel)
+	return nil
+}
+
+func (s *HotelStore) InsertHotel(ctx context.Context, hotel *types.Hotel) (*types.Hotel, error) {
+	id, err := newID(hotel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hotels[id]; ok {
+		return nil, errDuplicateKey
+	}
+	hotel.ID = id
+	s.hotels[id] = copyHotel(*hotel)
+	return hotel, nil
+}
+
+func (s *HotelStore) GetHotel(ctx context.Context, hotelID string) (*types.Hotel, error) {
+	if _, err := primitive.ObjectIDFromHex(hotelID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hotel, ok := s.hotels[hotelID]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	hotel = copyHotel(hotel)
+	return &hotel, nil
+}
+
+func (s *HotelStore) UpdateHotel(ctx context.Context, hotel *types.Hotel) error {
+	if _, err := primitive.ObjectIDFromHex(hotel.ID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hotels[hotel.ID]; !ok {
+		return types.ErrNotFound
+	}
+	s.hotels[hotel.ID] = copyHotel(*hotel)
+	return nil
+}
+
+func (s *HotelStore) DeleteHotel(ctx context.Context, hotelID string) error {
+	if _, err := primitive.ObjectIDFromHex(hotelID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.hotels, hotelID)
+	return nil
+}
+
+func (s *HotelStore) GetHotels(ctx context.Context) ([]*types.Hotel, error) {
+	return s.filter(func(*types.Hotel) bool { return true }), nil
+}
+
+func (s *HotelStore) QueryHotels(ctx context.Context, criteria types.QueryCriteria) ([]*types.HotelSearchResult, error) {
+	hotels := s.filter(func(h *types.Hotel) bool {
+		if criteria.Rating != 0 && h.Rating != criteria.Rating {
+			return false
+		}
+		return criteria.Location == "" || strings.EqualFold(h.Location, criteria.Location)
+	})
+
+	var rooms []*types.Room
+	booked := map[string]bool{}
+	for _, hotel := range hotels {
+		hotelRooms, err := s.rooms.GetRoomsByHotelID(ctx, hotel.ID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range hotelRooms {
+			room := &hotelRooms[i]
+			rooms = append(rooms, room)
+
+			if !criteria.HasDates() {
+				continue
+			}
+			bookings, err := s.bookings.GetActiveBookingsByRoomAndTimeRange(ctx, room.ID, criteria.From, criteria.Till)
+			if err != nil {
+				return nil, err
+			}
+			booked[room.ID] = len(bookings) > 0
+		}
+	}
+
+	return db.SearchResults(criteria, hotels, rooms, booked), nil
+}
+
+// filter returns copies of the hotels matching keep, in insertion order.
+func (s *HotelStore) filter(keep func(*types.Hotel) bool) []*types.Hotel {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hotels []*types.Hotel
+	for _, h := range s.hotels {
+		h := copyHotel(h)
+		if keep(&h) {
+			hotels = append(hotels, &h)
+		}
+	}
+	sort.Slice(hotels, func(i, j int) bool {
+		return lessID(hotels[i].ID, hotels[j].ID)
+	})
+	return hotels
+}
+
+// copyHotel drops Rooms, which is not stored, like the bson:"-" tag does.
+func copyHotel(h types.Hotel) types.Hotel {
+	h.Rooms = nil
+	return h
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var _ db.LoginAttemptStore = (*LoginAttemptStore)(nil)
+
+// LoginAttemptStore is a thread-safe, in-memory implementation of db.LoginAttemptStore.
+type LoginAttemptStore struct {
+	mu       sync.RWMutex
+	attempts map[string]types.LoginAttempts
+}
+
+func NewLoginAttemptStore() *LoginAttemptStore {
+	return &LoginAttemptStore{
+		attempts: make(map[string]types.LoginAttempts),
+	}
+}
+
+func (s *LoginAttemptStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts = make(map[string]types.LoginAttempts)
+	return nil
+}
+
+func (s *LoginAttemptStore) GetLoginAttempts(ctx context.Context, key string) (*types.LoginAttempts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	attempts, ok := s.attempts[key]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	attempts = copyLoginAttempts(attempts)
+	return &attempts, nil
+}
+
+func (s *LoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, at time.Time) (*types.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[key]
+	attempts.Key = key
+	attempts.Failures++
+	attempts.LastFailureAt = at
+	s.attempts[key] = attempts
+
+	attempts = copyLoginAttempts(attempts)
+	return &attempts, nil
+}
+
+func (s *LoginAttemptStore) LockLogin(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempts, ok := s.attempts[key]; ok {
+		attempts.LockedUntil = &until
+		s.attempts[key] = attempts
+	}
+	return nil
+}
+
+func (s *LoginAttemptStore) ResetLoginAttempts(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+func copyLoginAttempts(a types.LoginAttempts) types.LoginAttempts {
+	if a.LockedUntil != nil {
+		until := *a.LockedUntil
+		a.LockedUntil = &until
+	}
+	return a
+}
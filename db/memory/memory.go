@@ -0,0 +1,53 @@
+// Package memory provides thread-safe, in-memory implementations of the db
+// store interfaces. They follow the semantics of the Mongo stores (ObjectID hex
+// IDs, types.ErrNotFound for missing documents) and are meant for tests and
+// local demos where no MongoDB is available.
+package memory
+
+import (
+	"errors"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errDuplicateKey = errors.New("duplicate key")
+
+// newID returns a fresh ObjectID hex string, or validates a preset one.
+func newID(preset string) (string, error) {
+	if preset == "" {
+		return primitive.NewObjectID().Hex(), nil
+	}
+	if _, err := primitive.ObjectIDFromHex(preset); err != nil {
+		return "", err
+	}
+	return preset, nil
+}
+
+// lessID orders hex ObjectIDs by creation, matching Mongo's natural order.
+func lessID(a, b string) bool {
+	return a < b
+}
+
+// NewStores returns a complete, empty set of in-memory stores.
+func NewStores() db.Stores {
+	rooms := NewRoomStore()
+	bookings := NewBookingStore()
+
+	return db.Stores{
+		HotelStore:   NewHotelStore(rooms, bookings),
+		RoomStore:    rooms,
+		UserStore:    NewUserStore(),
+		BookingStore: bookings,
+
+		ReservationStore: NewReservationStore(),
+		SessionStore:     NewSessionStore(),
+		UserTokenStore:   NewUserTokenStore(),
+		OutboxStore:      NewOutboxStore(),
+
+		LoginAttemptStore: NewLoginAttemptStore(),
+		SettingsStore:     NewSettingsStore(),
+		APIKeyStore:       NewAPIKeyStore(),
+		OIDCLoginStore:    NewOIDCLoginStore(),
+	}
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var _ db.OIDCLoginStore = (*OIDCLoginStore)(nil)
+
+// OIDCLoginStore is a thread-safe, in-memory implementation of
+// db.OIDCLoginStore.
+type OIDCLoginStore struct {
+	mu     sync.Mutex
+	logins map[string]types.OIDCLogin
+}
+
+func NewOIDCLoginStore() *OIDCLoginStore {
+	return &OIDCLoginStore{
+		logins: make(map[string]types.OIDCLogin),
+	}
+}
+
+func (s *OIDCLoginStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logins = make(map[string]types.OIDCLogin)
+	return nil
+}
+
+func (s *OIDCLoginStore) InsertOIDCLogin(ctx context.Context, login *types.OIDCLogin) (*types.OIDCLogin, error) {
+	id, err := newID(login.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.logins[id]; ok {
+		return nil, errDuplicateKey
+	}
+	login.ID = id
+	s.logins[id] = *login
+	return login, nil
+}
+
+func (s *OIDCLoginStore) TakeOIDCLogin(ctx context.Context, stateHash string, at time.Time) (*types.OIDCLogin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, login := range s.logins {
+		if login.StateHash != stateHash {
+			continue
+		}
+		if login.UsedAt != nil || !at.Before(login.ExpiresAt) {
+			return nil, types.ErrInvalidOIDCState
+		}
+		login.UsedAt = &at
+		s.logins[id] = login
+		return &login, nil
+	}
+	return nil, types.ErrInvalidOIDCState
+}
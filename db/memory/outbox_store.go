@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var _ db.OutboxStore = (*OutboxStore)(nil)
+
+// OutboxStore is a thread-safe, in-memory implementation of db.OutboxStore.
+type OutboxStore struct {
+	mu            sync.RWMutex
+	notifications map[string]types.Notification
+}
+
+func NewOutboxStore() *OutboxStore {
+	return &OutboxStore{
+		notifications: make(map[string]types.Notification),
+	}
+}
+
+func (s *OutboxStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notifications = make(map[string]types.Notification)
+	return nil
+}
+
+func (s *OutboxStore) InsertNotification(ctx context.Context, notification *types.Notification) (*types.Notification, error) {
+	id, err := newID(notification.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.notifications[id]; ok {
+		return nil, errDuplicateKey
+	}
+	notification.ID = id
+	s.notifications[id] = copyNotification(*notification)
+	return notification, nil
+}
+
+func (s *OutboxStore) GetPendingNotifications(ctx context.Context, maxAttempts, limit int) ([]*types.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var notifications []*types.Notification
+	for _, n := range s.notifications {
+		if n.SentAt == nil && n.ExpiredAt == nil && n.Attempts < maxAttempts {
+			n := copyNotification(n)
+			notifications = append(notifications, &n)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return lessID(notifications[i].ID, notifications[j].ID)
+	})
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (s *OutboxStore) MarkNotificationSent(ctx context.Context, notificationID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notifications[notificationID]
+	if !ok {
+		return types.ErrNotFound
+	}
+	n.SentAt = &at
+	n.Body = ""
+	s.notifications[notificationID] = n
+	return nil
+}
+
+func (s *OutboxStore) ExpireNotifications(ctx context.Context, at time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired int64
+	for id, n := range s.notifications {
+		if n.SentAt != nil || n.ExpiredAt != nil || n.ExpiresAt.IsZero() || n.ExpiresAt.After(at) {
+			continue
+		}
+		expiredAt := at
+		n.ExpiredAt = &expiredAt
+		n.Body = ""
+		s.notifications[id] = n
+		expired++
+	}
+	return expired, nil
+}
+
+func (s *OutboxStore) RecordNotificationFailure(ctx context.Context, notificationID string, sendErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, ok := s.notifications[notificationID]
+	if !ok {
+		return types.ErrNotFound
+	}
+	n.Attempts++
+	n.LastError = sendErr
+	s.notifications[notificationID] = n
+	return nil
+}
+
+func copyNotification(n types.Notification) types.Notification {
+	if n.SentAt != nil {
+		at := *n.SentAt
+		n.SentAt = &at
+	}
+	if n.ExpiredAt != nil {
+		at := *n.ExpiredAt
+		n.ExpiredAt = &at
+	}
+	return n
+}
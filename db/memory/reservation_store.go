@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.ReservationStore = (*ReservationStore)(nil)
+
+// ReservationStore is a thread-safe, in-memory implementation of db.ReservationStore.
+type ReservationStore struct {
+	mu           sync.RWMutex
+	reservations map[string]types.Reservation
+}
+
+func NewReservationStore() *ReservationStore {
+	return &ReservationStore{
+		reservations: make(map[string]types.Reservation),
+	}
+}
+
+func (s *ReservationStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reservations = make(map[string]types.Reservation)
+	return nil
+}
+
+func (s *ReservationStore) InsertReservation(ctx context.Context, reservation *types.Reservation) (*types.Reservation, error) {
+	id, err := newID(reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reservations[id]; ok {
+		return nil, errDuplicateKey
+	}
+	reservation.ID = id
+	s.reservations[id] = copyReservation(*reservation)
+	return reservation, nil
+}
+
+func (s *ReservationStore) GetReservationByID(ctx context.Context, reservationID string) (*types.Reservation, error) {
+	if _, err := primitive.ObjectIDFromHex(reservationID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reservation, ok := s.reservations[reservationID]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	reservation = copyReservation(reservation)
+	return &reservation, nil
+}
+
+func (s *ReservationStore) GetReservationsByUserID(ctx context.Context, userID string) ([]*types.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var reservations []*types.Reservation
+	for _, r := range s.reservations {
+		if r.UserID == userID {
+			r := copyReservation(r)
+			reservations = append(reservations, &r)
+		}
+	}
+	sort.Slice(reservations, func(i, j int) bool {
+		return lessID(reservations[i].ID, reservations[j].ID)
+	})
+	return reservations, nil
+}
+
+func (s *ReservationStore) UpdateReservation(ctx context.Context, reservation *types.Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reservations[reservation.ID]; !ok {
+		return types.ErrNotFound
+	}
+	s.reservations[reservation.ID] = copyReservation(*reservation)
+	return nil
+}
+
+func (s *ReservationStore) DeleteReservationByID(ctx context.Context, reservationID string) error {
+	if _, err := primitive.ObjectIDFromHex(reservationID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.reservations, reservationID)
+	return nil
+}
+
+func copyReservation(r types.Reservation) types.Reservation {
+	r.BookingIDs = append([]string(nil), r.BookingIDs...)
+	return r
+}
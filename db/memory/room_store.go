@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.RoomStore = (*RoomStore)(nil)
+
+// RoomStore is a thread-safe, in-memory implementation of db.RoomStore.
+type RoomStore struct {
+	mu    sync.RWMutex
+	rooms map[string]types.Room
+}
+
+func NewRoomStore() *RoomStore {
+	return &RoomStore{
+		rooms: make(map[string]types.Room),
+	}
+}
+
+func (s *RoomStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rooms = make(map[string]types.Room)
+	return nil
+}
+
+func (s *RoomStore) InsertRoom(ctx context.Context, room *types.Room) (*types.Room, error) {
+	id, err := newID(room.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rooms[id]; ok {
+		return nil, errDuplicateKey
+	}
+	room.ID = id
+	s.rooms[id] = *room
+	return room, nil
+}
+
+func (s *RoomStore) GetRoomByID(ctx context.Context, roomID string) (*types.Room, error) {
+	if _, err := primitive.ObjectIDFromHex(roomID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.rooms[roomID]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return &room, nil
+}
+
+func (s *RoomStore) DeleteRoom(ctx context.Context, roomID string) error {
+	if _, err := primitive.ObjectIDFromHex(roomID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.rooms, roomID)
+	return nil
+}
+
+func (s *RoomStore) UpdateRoom(ctx context.Context, room *types.Room) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.rooms[room.ID]
+	if !ok {
+		return types.ErrNotFound
+	}
+	// Like the Mongo store, an update leaves the room open or closing
+	updated := *room
+	updated.Closing = stored.Closing
+	s.rooms[room.ID] = updated
+	return nil
+}
+
+func (s *RoomStore) SetRoomClosing(ctx context.Context, roomID string, closing bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, ok := s.rooms[roomID]
+	if !ok {
+		return types.ErrNotFound
+	}
+	room.Closing = closing
+	s.rooms[roomID] = room
+	return nil
+}
+
+func (s *RoomStore) GetRoomsByHotelID(ctx context.Context, hotelID string) ([]types.Room, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rooms []types.Room
+	for _, room := range s.rooms {
+		if room.HotelID == hotelID {
+			rooms = append(rooms, room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return lessID(rooms[i].ID, rooms[j].ID)
+	})
+	return rooms, nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.SessionStore = (*SessionStore)(nil)
+
+// SessionStore is a thread-safe, in-memory implementation of db.SessionStore.
+type SessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]types.Session
+}
+
+func NewSessionStore() *SessionStore {
+	return &SessionStore{
+		sessions: make(map[string]types.Session),
+	}
+}
+
+func (s *SessionStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions = make(map[string]types.Session)
+	return nil
+}
+
+func (s *SessionStore) InsertSession(ctx context.Context, session *types.Session) (*types.Session, error) {
+	id, err := newID(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id]; ok {
+		return nil, errDuplicateKey
+	}
+	session.ID = id
+	s.sessions[id] = copySession(*session)
+	return session, nil
+}
+
+func (s *SessionStore) GetSessionByID(ctx context.Context, sessionID string) (*types.Session, error) {
+	if _, err := primitive.ObjectIDFromHex(sessionID); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	session = copySession(session)
+	return &session, nil
+}
+
+func (s *SessionStore) RotateSession(ctx context.Context, sessionID, oldHash, newHash, accessTokenID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.RevokedAt != nil || session.RefreshTokenHash != oldHash {
+		return types.ErrSessionRevoked
+	}
+	session.RefreshTokenHash = newHash
+	session.AccessTokenID = accessTokenID
+	s.sessions[sessionID] = session
+	return nil
+}
+
+func (s *SessionStore) RevokeSession(ctx context.Context, sessionID string, at time.Time) error {
+	if _, err := primitive.ObjectIDFromHex(sessionID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[sessionID]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &at
+		s.sessions[sessionID] = session
+	}
+	return nil
+}
+
+func (s *SessionStore) RevokeUserSessions(ctx context.Context, userID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			s.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+func copySession(s types.Session) types.Session {
+	if s.RevokedAt != nil {
+		at := *s.RevokedAt
+		s.RevokedAt = &at
+	}
+	return s
+}
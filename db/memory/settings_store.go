@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+var _ db.SettingsStore = (*SettingsStore)(nil)
+
+// SettingsStore is a thread-safe, in-memory implementation of db.SettingsStore.
+type SettingsStore struct {
+	mu        sync.RWMutex
+	mfaPolicy types.MFAPolicy
+}
+
+func NewSettingsStore() *SettingsStore {
+	return &SettingsStore{}
+}
+
+func (s *SettingsStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mfaPolicy = types.MFAPolicy{}
+	return nil
+}
+
+func (s *SettingsStore) GetMFAPolicy(ctx context.Context) (*types.MFAPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	policy := s.mfaPolicy
+	policy.RequiredRoles = append([]types.Role(nil), policy.RequiredRoles...)
+	return &policy, nil
+}
+
+func (s *SettingsStore) UpdateMFAPolicy(ctx context.Context, policy *types.MFAPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mfaPolicy = *policy
+	s.mfaPolicy.RequiredRoles = append([]types.Role(nil), policy.RequiredRoles...)
+	return nil
+}
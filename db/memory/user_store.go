@@ -0,0 +1,348 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.UserStore = (*UserStore)(nil)
+
+// UserStore is a thread-safe, in-memory implementation of db.UserStore.
+type UserStore struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]types.User
+}
+
+func NewUserStore() *UserStore {
+	return &UserStore{
+		users: make(map[primitive.ObjectID]types.User),
+	}
+}
+
+func (s *UserStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = make(map[primitive.ObjectID]types.User)
+	return nil
+}
+
+func (s *UserStore) GetUserByID(ctx context.Context, ID string) (*types.User, error) {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *UserStore) GetUserByEmail(ctx context.Context, email string) (*types.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.sorted() {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, types.ErrNotFound
+}
+
+func (s *UserStore) GetUsers(ctx context.Context) ([]*types.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.sorted(), nil
+}
+
+// GetUsersWithPagination mirrors the Mongo implementation: users are sorted by
+// the bson field named in filter.SortBy, falling back to insertion order.
+func (s *UserStore) GetUsersWithPagination(ctx context.Context, filter types.UsersPaginationFilter) ([]*types.User, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	users := s.sorted()
+	s.mu.RUnlock()
+
+	if key := userSortKey(filter.SortBy); key != nil {
+		sort.SliceStable(users, func(i, j int) bool {
+			if filter.SortDir == types.DescSort {
+				return key(users[i]) > key(users[j])
+			}
+			return key(users[i]) < key(users[j])
+		})
+	}
+
+	offset := (filter.Page - 1) * filter.PageSize
+	if offset >= len(users) {
+		return nil, nil
+	}
+	end := offset + filter.PageSize
+	if end > len(users) {
+		end = len(users)
+	}
+	return users[offset:end], nil
+}
+
+func (s *UserStore) InsertUser(ctx context.Context, user *types.User) (*types.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	if _, ok := s.users[user.ID]; ok {
+		return nil, errDuplicateKey
+	}
+	for _, u := range s.users {
+		if u.Email == user.Email {
+			return nil, types.ErrEmailTaken
+		}
+	}
+	s.users[user.ID] = *user
+	return user, nil
+}
+
+func (s *UserStore) DeleteUser(ctx context.Context, ID string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, oid)
+	return nil
+}
+
+func (s *UserStore) UpdateUser(ctx context.Context, ID string, updateFields types.UpdateUserParams) (*types.User, error) {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return nil, types.ErrNotFound
+	}
+	if len(updateFields.FirstName) > 0 {
+		u.FirstName = updateFields.FirstName
+	}
+	if len(updateFields.LastName) > 0 {
+		u.LastName = updateFields.LastName
+	}
+	s.users[oid] = u
+	return &u, nil
+}
+
+func (s *UserStore) UpdateUserRole(ctx context.Context, ID string, role types.Role, hotelIDs []string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return types.ErrNotFound
+	}
+	u.Role = role
+	u.HotelIDs = append([]string(nil), hotelIDs...)
+	u.IsAdmin = role == types.RoleAdmin
+	s.users[oid] = u
+	return nil
+}
+
+func (s *UserStore) UpdateUserPassword(ctx context.Context, ID string, encryptedPassword string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return types.ErrNotFound
+	}
+	u.EncryptedPassword = encryptedPassword
+	s.users[oid] = u
+	return nil
+}
+
+func (s *UserStore) UpdateUserStatus(ctx context.Context, ID string, status types.AccountStatus, change types.AccountStatusChange) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return types.ErrNotFound
+	}
+	u.Status = status
+	u.StatusChange = &change
+	s.users[oid] = u
+	return nil
+}
+
+func (s *UserStore) UpdateUserMFA(ctx context.Context, ID string, mfa *types.UserMFA) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return types.ErrNotFound
+	}
+	u.MFA = copyUserMFA(mfa)
+	s.users[oid] = u
+	return nil
+}
+
+func (s *UserStore) UseMFAStep(ctx context.Context, ID string, step int64) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok || u.MFA == nil || u.MFA.LastUsedStep >= step {
+		return types.ErrInvalidMFACode
+	}
+	u.MFA = copyUserMFA(u.MFA)
+	u.MFA.LastUsedStep = step
+	s.users[oid] = u
+	return nil
+}
+
+func (s *UserStore) UseRecoveryCode(ctx context.Context, ID string, codeHash string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok || u.MFA == nil {
+		return types.ErrInvalidMFACode
+	}
+	for i, hash := range u.MFA.RecoveryCodeHashes {
+		if hash == codeHash {
+			u.MFA = copyUserMFA(u.MFA)
+			u.MFA.RecoveryCodeHashes = append(u.MFA.RecoveryCodeHashes[:i], u.MFA.RecoveryCodeHashes[i+1:]...)
+			s.users[oid] = u
+			return nil
+		}
+	}
+	return types.ErrInvalidMFACode
+}
+
+func (s *UserStore) GetUserByIdentity(ctx context.Context, provider string, subject string) (*types.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		for _, identity := range u.Identities {
+			if identity.Provider == provider && identity.Subject == subject {
+				return &u, nil
+			}
+		}
+	}
+	return nil, types.ErrNotFound
+}
+
+func (s *UserStore) AddUserIdentity(ctx context.Context, ID string, identity types.ExternalIdentity) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[oid]
+	if !ok {
+		return types.ErrNotFound
+	}
+	u.Identities = append(append([]types.ExternalIdentity(nil), u.Identities...), identity)
+	s.users[oid] = u
+	return nil
+}
+
+// copyUserMFA returns a copy, so updates never change users already handed
+// out.
+func copyUserMFA(mfa *types.UserMFA) *types.UserMFA {
+	if mfa == nil {
+		return nil
+	}
+	c := *mfa
+	c.RecoveryCodeHashes = append([]string(nil), mfa.RecoveryCodeHashes...)
+	if mfa.EnabledAt != nil {
+		at := *mfa.EnabledAt
+		c.EnabledAt = &at
+	}
+	return &c
+}
+
+// sorted returns copies of all users in insertion order. ObjectIDs start with
+// their creation timestamp, so ordering by ID matches Mongo's natural order.
+// The caller must hold s.mu.
+func (s *UserStore) sorted() []*types.User {
+	users := make([]*types.User, 0, len(s.users))
+	for _, u := range s.users {
+		u := u
+		users = append(users, &u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return bytes.Compare(users[i].ID[:], users[j].ID[:]) < 0
+	})
+	return users
+}
+
+func userSortKey(field string) func(*types.User) string {
+	switch field {
+	case "firstName":
+		return func(u *types.User) string { return u.FirstName }
+	case "lastName":
+		return func(u *types.User) string { return u.LastName }
+	case "email":
+		return func(u *types.User) string { return u.Email }
+	default:
+		return nil
+	}
+}
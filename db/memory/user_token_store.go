@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ db.UserTokenStore = (*UserTokenStore)(nil)
+
+// UserTokenStore is a thread-safe, in-memory implementation of db.UserTokenStore.
+type UserTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]types.UserToken
+}
+
+func NewUserTokenStore() *UserTokenStore {
+	return &UserTokenStore{
+		tokens: make(map[string]types.UserToken),
+	}
+}
+
+func (s *UserTokenStore) Drop(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens = make(map[string]types.UserToken)
+	return nil
+}
+
+func (s *UserTokenStore) InsertUserToken(ctx context.Context, token *types.UserToken) (*types.UserToken, error) {
+	id, err := newID(token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tokens[id]; ok {
+		return nil, errDuplicateKey
+	}
+	token.ID = id
+	s.tokens[id] = copyUserToken(*token)
+	return token, nil
+}
+
+func (s *UserTokenStore) GetUserTokenByHash(ctx context.Context, purpose types.TokenPurpose, tokenHash string) (*types.UserToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash {
+			token = copyUserToken(token)
+			return &token, nil
+		}
+	}
+	return nil, types.ErrNotFound
+}
+
+func (s *UserTokenStore) UseUserToken(ctx context.Context, tokenID string, at time.Time) error {
+	if _, err := primitive.ObjectIDFromHex(tokenID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[tokenID]
+	if !ok || token.UsedAt != nil {
+		return types.ErrInvalidUserToken
+	}
+	token.UsedAt = &at
+	s.tokens[tokenID] = token
+	return nil
+}
+
+func (s *UserTokenStore) UseUserTokens(ctx context.Context, userID string, purpose types.TokenPurpose, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, token := range s.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &at
+			s.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func copyUserToken(t types.UserToken) types.UserToken {
+	if t.UsedAt != nil {
+		at := *t.UsedAt
+		t.UsedAt = &at
+	}
+	return t
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type OIDCLoginStore interface {
+	InsertOIDCLogin(ctx context.Context, login *types.OIDCLogin) (*types.OIDCLogin, error)
+
+	// TakeOIDCLogin marks the unused, unexpired login with the state hash used
+	// and returns it, or returns types.ErrInvalidOIDCState, so each login
+	// completes once.
+	TakeOIDCLogin(ctx context.Context, stateHash string, at time.Time) (*types.OIDCLogin, error)
+}
+
+type MongoOIDCLoginStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoOIDCLoginStore(client *mongo.Client, dbName string, collName string) *MongoOIDCLoginStore {
+
+	return &MongoOIDCLoginStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoOIDCLoginStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoOIDCLoginStore) InsertOIDCLogin(ctx context.Context, login *types.OIDCLogin) (*types.OIDCLogin, error) {
+	result, err := s.coll.InsertOne(ctx, login)
+	if err != nil {
+		log.Printf("Error inserting OIDC login: %v\n", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("could not convert InsertedID to ObjectID")
+	}
+	login.ID = insertedID.Hex()
+	return login, nil
+}
+
+func (s *MongoOIDCLoginStore) TakeOIDCLogin(ctx context.Context, stateHash string, at time.Time) (*types.OIDCLogin, error) {
+	filter := bson.M{
+		"state_hash": stateHash,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": at},
+	}
+
+	var login types.OIDCLogin
+	err := s.coll.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": at}}).Decode(&login)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrInvalidOIDCState
+		}
+		log.Printf("Error taking OIDC login: %v\n", err)
+		return nil, err
+	}
+	login.UsedAt = &at
+	return &login, nil
+}
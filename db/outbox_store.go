@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxStore keeps notifications until they are sent, so a message is not
+// lost when sending fails or the process stops.
+type OutboxStore interface {
+	InsertNotification(ctx context.Context, notification *types.Notification) (*types.Notification, error)
+
+	// GetPendingNotifications returns up to limit unsent, unexpired
+	// notifications that failed fewer than maxAttempts times, oldest first.
+	GetPendingNotifications(ctx context.Context, maxAttempts, limit int) ([]*types.Notification, error)
+
+	// MarkNotificationSent records the send and empties the body.
+	MarkNotificationSent(ctx context.Context, notificationID string, at time.Time) error
+
+	// ExpireNotifications drops the unsent notifications whose ExpiresAt is
+	// not after at, emptying their bodies, and returns how many it dropped.
+	ExpireNotifications(ctx context.Context, at time.Time) (int64, error)
+
+	RecordNotificationFailure(ctx context.Context, notificationID string, sendErr string) error
+}
+
+type MongoOutboxStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoOutboxStore(client *mongo.Client, dbName string, collName string) *MongoOutboxStore {
+
+	return &MongoOutboxStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoOutboxStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoOutboxStore) InsertNotification(ctx context.Context, notification *types.Notification) (*types.Notification, error) {
+	result, err := s.coll.InsertOne(ctx, notification)
+	if err != nil {
+		log.Printf("Error inserting notification: %v\n", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("could not convert InsertedID to ObjectID")
+	}
+	notification.ID = insertedID.Hex()
+	return notification, nil
+}
+
+func (s *MongoOutboxStore) GetPendingNotifications(ctx context.Context, maxAttempts, limit int) ([]*types.Notification, error) {
+	filter := bson.M{
+		"sent_at":    bson.M{"$exists": false},
+		"expired_at": bson.M{"$exists": false},
+		"attempts":   bson.M{"$lt": maxAttempts},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := s.coll.Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("Error getting pending notifications: %v\n", err)
+		return nil, err
+	}
+
+	var notifications []*types.Notification
+	if err := cursor.All(ctx, &notifications); err != nil {
+		log.Printf("Error decoding notifications: %v\n", err)
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (s *MongoOutboxStore) MarkNotificationSent(ctx context.Context, ID string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"sent_at": at, "body": ""}}
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoOutboxStore) ExpireNotifications(ctx context.Context, at time.Time) (int64, error) {
+	filter := bson.M{
+		"sent_at":    bson.M{"$exists": false},
+		"expired_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$lte": at},
+	}
+	update := bson.M{"$set": bson.M{"expired_at": at, "body": ""}}
+
+	result, err := s.coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		log.Printf("Error expiring notifications: %v\n", err)
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (s *MongoOutboxStore) RecordNotificationFailure(ctx context.Context, ID string, sendErr string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"last_error": sendErr},
+	}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReservationStore interface {
+	InsertReservation(ctx context.Context, reservation *types.Reservation) (*types.Reservation, error)
+
+	GetReservationByID(ctx context.Context, reservationID string) (*types.Reservation, error)
+
+	GetReservationsByUserID(ctx context.Context, userID string) ([]*types.Reservation, error)
+
+	UpdateReservation(ctx context.Context, reservation *types.Reservation) error
+
+	DeleteReservationByID(ctx context.Context, reservationID string) error
+}
+
+type MongoReservationStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoReservationStore(client *mongo.Client, dbName string, collName string) *MongoReservationStore {
+
+	return &MongoReservationStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoReservationStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoReservationStore) InsertReservation(ctx context.Context, reservation *types.Reservation) (*types.Reservation, error) {
+	result, err := s.coll.InsertOne(ctx, reservation)
+	if err != nil {
+		log.Printf("Error inserting reservation: %v\n", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("could not convert InsertedID to ObjectID")
+	}
+	reservation.ID = insertedID.Hex()
+	return reservation, nil
+}
+
+func (s *MongoReservationStore) GetReservationByID(ctx context.Context, ID string) (*types.Reservation, error) {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var r types.Reservation
+	err = s.coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&r)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrNotFound
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *MongoReservationStore) GetReservationsByUserID(ctx context.Context, userID string) ([]*types.Reservation, error) {
+	cursor, err := s.coll.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		log.Printf("Error getting reservations by user ID: %v\n", err)
+		return nil, err
+	}
+
+	var reservations []*types.Reservation
+	if err := cursor.All(ctx, &reservations); err != nil {
+		log.Printf("Error decoding reservations: %v\n", err)
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+func (s *MongoReservationStore) UpdateReservation(ctx context.Context, reservation *types.Reservation) error {
+	oid, err := primitive.ObjectIDFromHex(reservation.ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"booking_ids": reservation.BookingIDs,
+			"status":      reservation.Status,
+			"guest":       reservation.Guest,
+		},
+	}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MongoReservationStore) DeleteReservationByID(ctx context.Context, ID string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.coll.DeleteOne(ctx, bson.M{"_id": oid})
+	return err
+}
@@ -16,6 +16,8 @@ type RoomStore interface {
 	GetRoomByID(ctx context.Context, roomID string) (*types.Room, error)
 	DeleteRoom(ctx context.Context, roomID string) error
 	UpdateRoom(ctx context.Context, room *types.Room) error
+	// SetRoomClosing opens or closes the room to new bookings.
+	SetRoomClosing(ctx context.Context, roomID string, closing bool) error
 	GetRoomsByHotelID(ctx context.Context, hotelID string) ([]types.Room, error)
 }
 
@@ -88,16 +90,55 @@ func (m *MongoRoomStore) DeleteRoom(ctx context.Context, roomID string) error {
 	return nil
 }
 
+func (m *MongoRoomStore) SetRoomClosing(ctx context.Context, roomID string, closing bool) error {
+	oid, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"closing": true}}
+	if !closing {
+		update = bson.M{"$unset": bson.M{"closing": ""}}
+	}
+
+	result, err := m.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		log.Printf("Error closing room: %v\n", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
 func (m *MongoRoomStore) UpdateRoom(ctx context.Context, room *types.Room) error {
-	oid := room.ID
+	oid, err := primitive.ObjectIDFromHex(room.ID)
+	if err != nil {
+		return err
+	}
 
 	filter := bson.M{"_id": oid}
-	update := bson.M{"$set": room}
-	_, err := m.coll.UpdateOne(ctx, filter, update)
+
+	// Exclude _id field from the update
+	update := bson.M{"$set": bson.M{
+		"hotel_id":      room.HotelID,
+		"number":        room.Number,
+		"floor":         room.Floor,
+		"type":          room.Type,
+		"description":   room.Description,
+		"price":         room.Price,
+		"rates":         room.Rates,
+		"max_occupancy": room.MaxOccupancy,
+	}}
+	result, err := m.coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		log.Printf("Error updating room: %v\n", err)
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
 	return nil
 }
 
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SessionStore interface {
+	InsertSession(ctx context.Context, session *types.Session) (*types.Session, error)
+
+	GetSessionByID(ctx context.Context, sessionID string) (*types.Session, error)
+
+	// RotateSession replaces the refresh token hash and access token ID of an
+	// active session, provided its refresh token hash is still oldHash.
+	RotateSession(ctx context.Context, sessionID, oldHash, newHash, accessTokenID string) error
+
+	RevokeSession(ctx context.Context, sessionID string, at time.Time) error
+
+	RevokeUserSessions(ctx context.Context, userID string, at time.Time) error
+}
+
+type MongoSessionStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoSessionStore(client *mongo.Client, dbName string, collName string) *MongoSessionStore {
+
+	return &MongoSessionStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoSessionStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+// InsertSession keeps a preset session ID, which the refresh token embeds.
+func (s *MongoSessionStore) InsertSession(ctx context.Context, session *types.Session) (*types.Session, error) {
+	oid := primitive.NewObjectID()
+	if session.ID != "" {
+		var err error
+		if oid, err = primitive.ObjectIDFromHex(session.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	doc, err := bsonWithID(session, oid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.coll.InsertOne(ctx, doc); err != nil {
+		log.Printf("Error inserting session: %v\n", err)
+		return nil, err
+	}
+	session.ID = oid.Hex()
+	return session, nil
+}
+
+func (s *MongoSessionStore) GetSessionByID(ctx context.Context, ID string) (*types.Session, error) {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var session types.Session
+	err = s.coll.FindOne(ctx, bson.M{"_id": oid}).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *MongoSessionStore) RotateSession(ctx context.Context, ID, oldHash, newHash, accessTokenID string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	// Matching on the old hash makes two refreshes with one token race safely
+	filter := bson.M{
+		"_id":                oid,
+		"refresh_token_hash": oldHash,
+		"revoked_at":         bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"refresh_token_hash": newHash,
+			"access_token_id":    accessTokenID,
+		},
+	}
+
+	result, err := s.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		log.Printf("Error rotating session: %v\n", err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return types.ErrSessionRevoked
+	}
+
+	return nil
+}
+
+func (s *MongoSessionStore) RevokeSession(ctx context.Context, ID string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": oid, "revoked_at": bson.M{"$exists": false}}
+	_, err = s.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		log.Printf("Error revoking session: %v\n", err)
+	}
+	return err
+}
+
+func (s *MongoSessionStore) RevokeUserSessions(ctx context.Context, userID string, at time.Time) error {
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	_, err := s.coll.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		log.Printf("Error revoking user sessions: %v\n", err)
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SettingsStore keeps settings admins change at runtime, one document each.
+type SettingsStore interface {
+	// GetMFAPolicy returns an empty policy if none was set.
+	GetMFAPolicy(ctx context.Context) (*types.MFAPolicy, error)
+
+	UpdateMFAPolicy(ctx context.Context, policy *types.MFAPolicy) error
+}
+
+const mfaPolicyID = "mfa_policy"
+
+type MongoSettingsStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoSettingsStore(client *mongo.Client, dbName string, collName string) *MongoSettingsStore {
+
+	return &MongoSettingsStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoSettingsStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoSettingsStore) GetMFAPolicy(ctx context.Context) (*types.MFAPolicy, error) {
+	var policy types.MFAPolicy
+	err := s.coll.FindOne(ctx, bson.M{"_id": mfaPolicyID}).Decode(&policy)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &types.MFAPolicy{}, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (s *MongoSettingsStore) UpdateMFAPolicy(ctx context.Context, policy *types.MFAPolicy) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := s.coll.ReplaceOne(ctx, bson.M{"_id": mfaPolicyID}, policy, opts)
+	if err != nil {
+		log.Printf("Error updating MFA policy: %v\n", err)
+	}
+	return err
+}
@@ -25,11 +25,33 @@ type UserStore interface {
 
 	GetUsersWithPagination(ctx context.Context, filter types.UsersPaginationFilter) ([]*types.User, error)
 
+	// InsertUser fails with types.ErrEmailTaken if another user has the email.
 	InsertUser(ctx context.Context, user *types.User) (*types.User, error)
 
 	DeleteUser(ctx context.Context, ID string) error
 
 	UpdateUser(ctx context.Context, ID string, updateFields types.UpdateUserParams) (*types.User, error)
+
+	UpdateUserRole(ctx context.Context, ID string, role types.Role, hotelIDs []string) error
+
+	UpdateUserPassword(ctx context.Context, ID string, encryptedPassword string) error
+
+	UpdateUserStatus(ctx context.Context, ID string, status types.AccountStatus, change types.AccountStatusChange) error
+
+	// UpdateUserMFA replaces the user's second factor; nil removes it.
+	UpdateUserMFA(ctx context.Context, ID string, mfa *types.UserMFA) error
+
+	// UseMFAStep records a TOTP code's time step as used. It returns
+	// types.ErrInvalidMFACode unless step is later than the last one used.
+	UseMFAStep(ctx context.Context, ID string, step int64) error
+
+	// UseRecoveryCode removes the recovery code hash, returning
+	// types.ErrInvalidMFACode if the user has no such code left.
+	UseRecoveryCode(ctx context.Context, ID string, codeHash string) error
+
+	GetUserByIdentity(ctx context.Context, provider string, subject string) (*types.User, error)
+
+	AddUserIdentity(ctx context.Context, ID string, identity types.ExternalIdentity) error
 }
 
 type MongoUserStore struct {
@@ -52,6 +74,16 @@ func NewMongoUserStore(client *mongo.Client, dbName string, collName string) *Mo
 func (s *MongoUserStore) Drop(c context.Context) error {
 	return s.coll.Drop(c)
 }
+
+// EnsureIndexes creates the unique index that keeps emails to one user. It
+// fails while two users share an email.
+func (s *MongoUserStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
 func (s *MongoUserStore) GetUserByID(ctx context.Context, ID string) (*types.User, error) {
 	oid, err := primitive.ObjectIDFromHex(ID)
 	if err != nil {
@@ -63,7 +95,7 @@ func (s *MongoUserStore) GetUserByID(ctx context.Context, ID string) (*types.Use
 
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("id not found")
+			return nil, types.ErrNotFound
 		}
 		return nil, err
 	}
@@ -77,7 +109,7 @@ func (s *MongoUserStore) GetUserByEmail(ctx context.Context, email string) (*typ
 
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("email not found")
+			return nil, types.ErrNotFound
 		}
 		return nil, err
 	}
@@ -116,6 +148,9 @@ func (s *MongoUserStore) InsertUser(ctx context.Context, user *types.User) (*typ
 	res, err := s.coll.InsertOne(ctx, user)
 
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, types.ErrEmailTaken
+		}
 		return nil, err
 	}
 
@@ -147,6 +182,153 @@ func (s *MongoUserStore) UpdateUser(ctx context.Context, ID string, updateFields
 	return updatedUser, nil
 }
 
+func (s *MongoUserStore) UpdateUserRole(ctx context.Context, ID string, role types.Role, hotelIDs []string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"role":     role,
+		"hotelIDs": hotelIDs,
+		"isAdmin":  role == types.RoleAdmin,
+	}}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoUserStore) UpdateUserPassword(ctx context.Context, ID string, encryptedPassword string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"EncryptedPassword": encryptedPassword}}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoUserStore) UpdateUserStatus(ctx context.Context, ID string, status types.AccountStatus, change types.AccountStatusChange) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"status":       status,
+		"statusChange": change,
+	}}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoUserStore) UpdateUserMFA(ctx context.Context, ID string, mfa *types.UserMFA) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"mfa": mfa}}
+	if mfa == nil {
+		update = bson.M{"$unset": bson.M{"mfa": ""}}
+	}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
+func (s *MongoUserStore) UseMFAStep(ctx context.Context, ID string, step int64) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	// Matching on the last step makes two logins with one code race safely
+	filter := bson.M{"_id": oid, "mfa.lastUsedStep": bson.M{"$lt": step}}
+	result, err := s.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"mfa.lastUsedStep": step}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MongoUserStore) UseRecoveryCode(ctx context.Context, ID string, codeHash string) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": oid, "mfa.recoveryCodeHashes": codeHash}
+	result, err := s.coll.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"mfa.recoveryCodeHashes": codeHash}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MongoUserStore) GetUserByIdentity(ctx context.Context, provider string, subject string) (*types.User, error) {
+	filter := bson.M{"identities": bson.M{"$elemMatch": bson.M{"provider": provider, "subject": subject}}}
+
+	var u types.User
+	err := s.coll.FindOne(ctx, filter).Decode(&u)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (s *MongoUserStore) AddUserIdentity(ctx context.Context, ID string, identity types.ExternalIdentity) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.coll.UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$push": bson.M{"identities": identity}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return types.ErrNotFound
+	}
+	return nil
+}
+
 // GetUsersWithPagination retrieves users from the store with pagination using the provided filter.
 func (s *MongoUserStore) GetUsersWithPagination(ctx context.Context, filter types.UsersPaginationFilter) ([]*types.User, error) {
 	if err := filter.Validate(); err != nil {
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type UserTokenStore interface {
+	InsertUserToken(ctx context.Context, token *types.UserToken) (*types.UserToken, error)
+
+	GetUserTokenByHash(ctx context.Context, purpose types.TokenPurpose, tokenHash string) (*types.UserToken, error)
+
+	// UseUserToken marks the token used. It returns types.ErrInvalidUserToken
+	// if the token was already used, so a token is only ever used once.
+	UseUserToken(ctx context.Context, tokenID string, at time.Time) error
+
+	// UseUserTokens marks every unused token of the user with the purpose used.
+	UseUserTokens(ctx context.Context, userID string, purpose types.TokenPurpose, at time.Time) error
+}
+
+type MongoUserTokenStore struct {
+	client   *mongo.Client
+	collName string
+	dbName   string
+	coll     *mongo.Collection
+}
+
+func NewMongoUserTokenStore(client *mongo.Client, dbName string, collName string) *MongoUserTokenStore {
+
+	return &MongoUserTokenStore{
+		client:   client,
+		collName: collName,
+		dbName:   dbName,
+		coll:     client.Database(dbName).Collection(collName),
+	}
+}
+
+func (s *MongoUserTokenStore) Drop(c context.Context) error {
+	return s.coll.Drop(c)
+}
+
+func (s *MongoUserTokenStore) InsertUserToken(ctx context.Context, token *types.UserToken) (*types.UserToken, error) {
+	result, err := s.coll.InsertOne(ctx, token)
+	if err != nil {
+		log.Printf("Error inserting user token: %v\n", err)
+		return nil, err
+	}
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return nil, errors.New("could not convert InsertedID to ObjectID")
+	}
+	token.ID = insertedID.Hex()
+	return token, nil
+}
+
+func (s *MongoUserTokenStore) GetUserTokenByHash(ctx context.Context, purpose types.TokenPurpose, tokenHash string) (*types.UserToken, error) {
+	var token types.UserToken
+	err := s.coll.FindOne(ctx, bson.M{"purpose": purpose, "token_hash": tokenHash}).Decode(&token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *MongoUserTokenStore) UseUserToken(ctx context.Context, ID string, at time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(ID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": oid, "used_at": bson.M{"$exists": false}}
+	result, err := s.coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"used_at": at}})
+	if err != nil {
+		log.Printf("Error using user token: %v\n", err)
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return types.ErrInvalidUserToken
+	}
+	return nil
+}
+
+func (s *MongoUserTokenStore) UseUserTokens(ctx context.Context, userID string, purpose types.TokenPurpose, at time.Time) error {
+	filter := bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}}
+	_, err := s.coll.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"used_at": at}})
+	if err != nil {
+		log.Printf("Error using user tokens: %v\n", err)
+	}
+	return err
+}
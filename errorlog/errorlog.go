@@ -13,6 +13,8 @@ type AppError struct {
 	Err     error
 	Code    int
 	Message string
+	// Details is optional machine-readable context included in the response.
+	Details interface{}
 }
 
 func (e AppError) Error() string {
@@ -48,11 +50,16 @@ func (h *HTTPErrorResponseWriterAndLogger) HandleError(w http.ResponseWriter, er
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(err.Code)
 
+	body := map[string]interface{}{
+		"code":    err.Code,
+		"message": err.Message,
+	}
+	if err.Details != nil {
+		body["details"] = err.Details
+	}
+
 	errorResponse := map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    err.Code,
-			"message": err.Message,
-		},
+		"error": body,
 	}
 
 	json.NewEncoder(w).Encode(errorResponse)
@@ -105,3 +112,19 @@ func ForbiddenError(err error) AppError {
 		Message: "forbidden access. you don't have permission to access this resource.",
 	}
 }
+
+func ConflictError(err error) AppError {
+	return AppError{
+		Err:     err,
+		Code:    http.StatusConflict,
+		Message: "conflict. the resource is not in a state that allows this request.",
+	}
+}
+
+func TooManyRequestsError(err error) AppError {
+	return AppError{
+		Err:     err,
+		Code:    http.StatusTooManyRequests,
+		Message: "too many requests. please try again later.",
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// authenticateAPIKey lets a request with an API key act as the key's user,
+// limited to the key's scopes.
+func authenticateAPIKey(c *gin.Context, manager *business.Manager, key string) {
+	apiKey, user, err := manager.AuthenticateAPIKey(c, key)
+	if err != nil {
+		abortAuthError(c, err)
+		return
+	}
+
+	c.Set("userID", apiKey.UserID)
+	c.Set("apiKey", apiKey)
+	c.Set("role", user.EffectiveRole())
+	c.Set("hotelIDs", user.HotelIDs)
+	c.Next()
+}
+
+// UserOnlyMiddleware refuses API keys, for routes only a person who logged
+// in may use. It must run after AuthMiddleware.
+func UserOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isAPIKeyRequest(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed with an API key"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func isAPIKeyRequest(c *gin.Context) bool {
+	_, ok := c.Get("apiKey")
+	return ok
+}
+
+// inScope reports whether the request may use perm as far as its API key is
+// concerned. Requests with an access token are limited by role alone.
+func inScope(c *gin.Context, perm types.Permission) bool {
+	apiKey, ok := c.Get("apiKey")
+	if !ok {
+		return true
+	}
+	key, _ := apiKey.(*types.APIKey)
+	return key != nil && key.HasScope(perm)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/db/memory"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// newAPIKeyTestRouter returns a manager with an admin holding a key scoped to
+// viewing bookings, and a router checking permissions on it.
+func newAPIKeyTestRouter(t *testing.T) (*business.Manager, *gin.Engine, string, *types.NewAPIKey) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	manager := business.NewManager(memory.NewStores())
+	userID, err := manager.AddNewAdmin(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := manager.CreateAPIKey(ctx, userID, types.NewAPIKeyParams{
+		Name:   "reports",
+		UserID: userID,
+		Scopes: []types.Permission{types.PermViewBookings},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	engine := gin.New()
+	authed := engine.Group("", AuthMiddleware(manager))
+	authed.GET("/bookings", RequirePermission(types.PermViewBookings, nil), ok)
+	authed.GET("/users", RequirePermission(types.PermManageUsers, nil), ok)
+	authed.POST("/logout", UserOnlyMiddleware(), ok)
+	return manager, engine, userID, key
+}
+
+func serveWithAPIKey(engine *gin.Engine, method, path, key string) int {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(apiKeyHeader, key)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	_, engine, _, key := newAPIKeyTestRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		want   int
+	}{
+		{"in scope", http.MethodGet, "/bookings", key.Key, http.StatusOK},
+		// The admin's role grants it, but the key does not
+		{"out of scope", http.MethodGet, "/users", key.Key, http.StatusForbidden},
+		{"user only route", http.MethodPost, "/logout", key.Key, http.StatusForbidden},
+		{"unknown key", http.MethodGet, "/bookings", "hrk_unknown", http.StatusUnauthorized},
+		{"not a key", http.MethodGet, "/bookings", "unknown", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serveWithAPIKey(engine, tt.method, tt.path, tt.key); got != tt.want {
+				t.Fatalf("got status %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRevoked(t *testing.T) {
+	manager, engine, _, key := newAPIKeyTestRouter(t)
+
+	if err := manager.RevokeAPIKey(context.Background(), key.APIKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := serveWithAPIKey(engine, http.MethodGet, "/bookings", key.Key); got != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", got, http.StatusUnauthorized)
+	}
+}
+
+func TestAPIKeyUsesCurrentRole(t *testing.T) {
+	manager, engine, userID, key := newAPIKeyTestRouter(t)
+	ctx := context.Background()
+
+	// A guest may not view bookings, whatever the key was issued with
+	if _, err := manager.SetUserRole(ctx, userID, types.SetUserRoleParams{Role: types.RoleGuest}); err != nil {
+		t.Fatal(err)
+	}
+	if got := serveWithAPIKey(engine, http.MethodGet, "/bookings", key.Key); got != http.StatusForbidden {
+		t.Fatalf("got status %d, want %d", got, http.StatusForbidden)
+	}
+}
+
+func TestAPIKeySuspendedUser(t *testing.T) {
+	manager, engine, userID, key := newAPIKeyTestRouter(t)
+	ctx := context.Background()
+
+	if _, _, err := manager.SetAccountStatus(ctx, userID, userID, types.SetAccountStatusParams{Status: types.AccountSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	if got := serveWithAPIKey(engine, http.MethodGet, "/bookings", key.Key); got != http.StatusForbidden {
+		t.Fatalf("got status %d, want %d", got, http.StatusForbidden)
+	}
+}
@@ -1,74 +1,70 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 	"github.com/mkabdelrahman/hotel-reservation/auth"
 	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/types"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware accepts an access token whose session is still active and
+// whose ID (jti) is the session's current one, so tokens die on logout and on
+// refresh. It also accepts an API key in the X-API-Key header instead.
+func AuthMiddleware(manager *business.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			authenticateAPIKey(c, manager, key)
 			return
 		}
 
-		token, err := auth.ParseToken(tokenString)
-		if err != nil || !token.Valid {
+		// Accept both "Bearer <token>" and a bare token
+		tokenString := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if tokenString == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
-		if !auth.IsTokenNotExpired(token) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
-			c.Abort()
+		claims, err := manager.AuthenticateAccessToken(c, tokenString)
+		if err != nil {
+			abortAuthError(c, err)
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
-			return
+		role := types.Role(claims.Role)
+		if role == "" {
+			role = types.RoleGuest
 		}
-		c.Set("userID", claims["id"])
+		c.Set("userID", claims.Subject)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("role", role)
+		c.Set("hotelIDs", claims.HotelIDs)
 		c.Next()
 	}
 }
 
-func AdminOnlyMiddleware(manager *business.Manager) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Extract user ID from the authentication token or session
-
-		userID, ok := c.Get("userID")
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized HERE"})
-			c.Abort()
-			return
-		}
-
-		// Retrieve user by ID
-		user, err := manager.UserStore.GetUserByID(c, userID.(string))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
-			c.Abort()
-			return
-		}
-
-		// Check if the user is an admin
-		if user == nil || !user.IsAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied. Admins only"})
-			c.Abort()
-			return
-		}
-
-		// If the user is an admin, continue with the next middleware/handler
-		c.Next()
+func abortAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrTokenExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
+	case errors.Is(err, types.ErrSessionRevoked):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token revoked"})
+	case errors.Is(err, types.ErrInvalidAPIKey):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+	case errors.Is(err, types.ErrAccountSuspended):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account suspended"})
+	case errors.Is(err, types.ErrAccountDeleted):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account deleted"})
+	case errors.Is(err, types.ErrAccountNotVerified):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account email not verified"})
+	case errors.Is(err, auth.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
 	}
+	c.Abort()
 }
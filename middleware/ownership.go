@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// SelfOrPermissionMiddleware lets a user through to routes about the user
+// whose ID is the param, and users whose role grants perm through to all of
+// them. It must run after AuthMiddleware.
+func SelfOrPermissionMiddleware(param string, perm types.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowOwnerOr(c, c.Param(param), perm, "")
+	}
+}
+
+// SelfOnlyMiddleware lets a user through to routes about the user whose ID is
+// the param, and no one else, whatever their role. API keys are refused, as
+// these routes secure the account. It must run after AuthMiddleware.
+func SelfOnlyMiddleware(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetString("userID"); userID == "" || userID != c.Param(param) || isAPIKeyRequest(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// BookingOwnerMiddleware lets the user who made the booking whose ID is the
+// param through, and users whose role grants perm on the booking's hotel. It
+// must run after AuthMiddleware.
+func BookingOwnerMiddleware(manager *business.Manager, param string, perm types.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		booking, err := manager.BookingStore.GetBookingByID(c, c.Param(param))
+		if errors.Is(err, types.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+			c.Abort()
+			return
+		}
+
+		hotelID, err := bookingHotelID(c, manager, booking)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+			c.Abort()
+			return
+		}
+
+		allowOwnerOr(c, booking.UserID, perm, hotelID)
+	}
+}
+
+// ReservationOwnerMiddleware lets the user who made the reservation whose ID
+// is the param through, and users whose role grants perm across all hotels,
+// since a reservation may span several. It must run after AuthMiddleware.
+func ReservationOwnerMiddleware(manager *business.Manager, param string, perm types.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reservation, err := manager.ReservationStore.GetReservationByID(c, c.Param(param))
+		if errors.Is(err, types.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+			c.Abort()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+			c.Abort()
+			return
+		}
+
+		allowOwnerOr(c, reservation.UserID, perm, "")
+	}
+}
+
+// allowOwnerOr continues the chain if the authenticated user is ownerID or
+// their role grants perm on hotelID, and aborts it otherwise. An API key
+// needs perm in scope either way.
+func allowOwnerOr(c *gin.Context, ownerID string, perm types.Permission, hotelID string) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+
+	if id, _ := userID.(string); id != "" && id == ownerID && inScope(c, perm) {
+		c.Next()
+		return
+	}
+
+	if !authorized(c, perm, hotelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		c.Abort()
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/db/memory"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// asUser stands in for AuthMiddleware, authenticating every request as the
+// user with the role and hotels.
+func asUser(userID string, role types.Role, hotelIDs ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("role", role)
+		c.Set("hotelIDs", hotelIDs)
+		c.Next()
+	}
+}
+
+// serveAs serves a request to the route with the handlers, authenticated by
+// auth, and returns the status.
+func serveAs(auth gin.HandlerFunc, route, path string, handlers ...gin.HandlerFunc) int {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	handlers = append([]gin.HandlerFunc{auth}, handlers...)
+	handlers = append(handlers, func(c *gin.Context) { c.Status(http.StatusOK) })
+	engine.GET(route, handlers...)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestOwnershipMiddleware(t *testing.T) {
+	ctx := context.Background()
+	manager := business.NewManager(memory.NewStores())
+
+	ownerID, err := manager.AddNewUser(ctx, types.NewUserParams{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Password: "password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hotelID, err := manager.AddNewHotel(ctx, types.NewHotelParams{Name: "Dolcica", Location: "Madrid", Rating: types.Excellent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roomID, err := manager.AddNewRoom(ctx, types.NewRoomParams{Number: "101", Type: types.StandardRoom, Price: 100}, hotelID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Now().AddDate(0, 0, 7).Truncate(24 * time.Hour)
+	bookingID, err := manager.AddNewBooking(ctx, types.NewBookingParams{UserID: ownerID, RoomID: roomID, FromDate: from, TillDate: from.AddDate(0, 0, 2), Adults: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservation, err := manager.AddNewReservation(ctx, types.NewReservationParams{
+		UserID:   ownerID,
+		Lines:    []types.ReservationLine{{RoomID: roomID, Adults: 1}},
+		FromDate: from.AddDate(0, 0, 7),
+		TillDate: from.AddDate(0, 0, 9),
+		Guest:    types.GuestDetails{FirstName: "Ali", LastName: "Ibrahim", Email: "ali@example.com", Phone: "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherID := primitive.NewObjectID().Hex()
+	missingID := primitive.NewObjectID().Hex()
+
+	owner := asUser(ownerID, types.RoleGuest)
+	other := asUser(otherID, types.RoleGuest)
+	admin := asUser(otherID, types.RoleAdmin)
+	staff := asUser(otherID, types.RoleStaff, hotelID)
+	otherStaff := asUser(otherID, types.RoleStaff, primitive.NewObjectID().Hex())
+
+	booking := BookingOwnerMiddleware(manager, "id", types.PermViewBookings)
+	reservationOwner := ReservationOwnerMiddleware(manager, "id", types.PermManageBookings)
+	self := SelfOnlyMiddleware("id")
+
+	tests := []struct {
+		name       string
+		auth       gin.HandlerFunc
+		route      string
+		path       string
+		middleware gin.HandlerFunc
+		want       int
+	}{
+		{"booking owner", owner, "/booking/:id", "/booking/" + bookingID, booking, http.StatusOK},
+		{"booking other user", other, "/booking/:id", "/booking/" + bookingID, booking, http.StatusForbidden},
+		{"booking admin", admin, "/booking/:id", "/booking/" + bookingID, booking, http.StatusOK},
+		{"booking staff of its hotel", staff, "/booking/:id", "/booking/" + bookingID, booking, http.StatusOK},
+		{"booking staff of another hotel", otherStaff, "/booking/:id", "/booking/" + bookingID, booking, http.StatusForbidden},
+		{"booking missing", admin, "/booking/:id", "/booking/" + missingID, booking, http.StatusNotFound},
+
+		{"reservation owner", owner, "/reservation/:id", "/reservation/" + reservation.ID, reservationOwner, http.StatusOK},
+		{"reservation other user", other, "/reservation/:id", "/reservation/" + reservation.ID, reservationOwner, http.StatusForbidden},
+		{"reservation admin", admin, "/reservation/:id", "/reservation/" + reservation.ID, reservationOwner, http.StatusOK},
+		// Reservations may span hotels, so hotel staff are not let in
+		{"reservation hotel staff", staff, "/reservation/:id", "/reservation/" + reservation.ID, reservationOwner, http.StatusForbidden},
+		{"reservation missing", admin, "/reservation/:id", "/reservation/" + missingID, reservationOwner, http.StatusNotFound},
+
+		{"self", owner, "/user/:id", "/user/" + ownerID, self, http.StatusOK},
+		{"self other user", other, "/user/:id", "/user/" + ownerID, self, http.StatusForbidden},
+		{"self admin", admin, "/user/:id", "/user/" + ownerID, self, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serveAs(tt.auth, tt.route, tt.path, tt.middleware); got != tt.want {
+				t.Fatalf("got status %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+// HotelScope finds the hotel a request acts on, or returns "" when the
+// request is not about a single hotel.
+type HotelScope func(c *gin.Context) (string, error)
+
+// RequirePermission lets the request through if the authenticated user's role
+// grants perm on the hotel found by scope, or across all hotels when scope is
+// nil. It must run after AuthMiddleware.
+func RequirePermission(perm types.Permission, scope HotelScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hotelID string
+		if scope != nil {
+			var err error
+			hotelID, err = scope(c)
+			if errors.Is(err, types.ErrNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+				c.Abort()
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal Server Error"})
+				c.Abort()
+				return
+			}
+		}
+
+		if !authorized(c, perm, hotelID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HotelParam scopes requests to the hotel whose ID is the param.
+func HotelParam(param string) HotelScope {
+	return func(c *gin.Context) (string, error) {
+		return c.Param(param), nil
+	}
+}
+
+// RoomHotel scopes requests to the hotel of the room whose ID is the param.
+func RoomHotel(manager *business.Manager, param string) HotelScope {
+	return func(c *gin.Context) (string, error) {
+		room, err := manager.RoomStore.GetRoomByID(c, c.Param(param))
+		if err != nil {
+			return "", err
+		}
+		return room.HotelID, nil
+	}
+}
+
+// BookingHotel scopes requests to the hotel of the booking whose ID is the
+// param.
+func BookingHotel(manager *business.Manager, param string) HotelScope {
+	return func(c *gin.Context) (string, error) {
+		booking, err := manager.BookingStore.GetBookingByID(c, c.Param(param))
+		if err != nil {
+			return "", err
+		}
+		return bookingHotelID(c, manager, booking)
+	}
+}
+
+// bookingHotelID returns the hotel of the booking's room, or "" if the room
+// was deleted, which leaves the booking to roles that are not scoped.
+func bookingHotelID(c *gin.Context, manager *business.Manager, booking *types.Booking) (string, error) {
+	room, err := manager.RoomStore.GetRoomByID(c, booking.RoomID)
+	if errors.Is(err, types.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return room.HotelID, nil
+}
+
+// authorized reports whether the role and hotels set by AuthMiddleware grant
+// perm on hotelID, and the API key, if any, has perm in scope.
+func authorized(c *gin.Context, perm types.Permission, hotelID string) bool {
+	role, _ := c.Get("role")
+	hotelIDs, _ := c.Get("hotelIDs")
+
+	r, _ := role.(types.Role)
+	ids, _ := hotelIDs.([]string)
+	return types.Authorize(r, ids, perm, hotelID) && inScope(c, perm)
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mkabdelrahman/hotel-reservation/business"
+	"github.com/mkabdelrahman/hotel-reservation/db/memory"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRequirePermission(t *testing.T) {
+	ctx := context.Background()
+	manager := business.NewManager(memory.NewStores())
+
+	var hotelIDs, roomIDs []string
+	for _, name := range []string{"Dolcica", "Lapache"} {
+		hotelID, err := manager.AddNewHotel(ctx, types.NewHotelParams{Name: name, Location: "Madrid", Rating: types.Excellent})
+		if err != nil {
+			t.Fatal(err)
+		}
+		roomID, err := manager.AddNewRoom(ctx, types.NewRoomParams{Number: "101", Type: types.StandardRoom, Price: 100}, hotelID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hotelIDs = append(hotelIDs, hotelID)
+		roomIDs = append(roomIDs, roomID)
+	}
+
+	userID := primitive.NewObjectID().Hex()
+	guest := asUser(userID, types.RoleGuest)
+	hotelManager := asUser(userID, types.RoleHotelManager, hotelIDs[0])
+	admin := asUser(userID, types.RoleAdmin)
+
+	hotelRooms := RequirePermission(types.PermManageRooms, HotelParam("id"))
+	room := RequirePermission(types.PermManageRooms, RoomHotel(manager, "id"))
+	hotels := RequirePermission(types.PermManageHotels, nil)
+
+	tests := []struct {
+		name       string
+		auth       gin.HandlerFunc
+		route      string
+		path       string
+		middleware gin.HandlerFunc
+		want       int
+	}{
+		{"manager on their hotel", hotelManager, "/hotel/:id", "/hotel/" + hotelIDs[0], hotelRooms, http.StatusOK},
+		{"manager on another hotel", hotelManager, "/hotel/:id", "/hotel/" + hotelIDs[1], hotelRooms, http.StatusForbidden},
+		{"manager on a room of their hotel", hotelManager, "/room/:id", "/room/" + roomIDs[0], room, http.StatusOK},
+		{"manager on a room of another hotel", hotelManager, "/room/:id", "/room/" + roomIDs[1], room, http.StatusForbidden},
+		{"manager creating hotels", hotelManager, "/hotel", "/hotel", hotels, http.StatusForbidden},
+		{"guest on a hotel", guest, "/hotel/:id", "/hotel/" + hotelIDs[0], hotelRooms, http.StatusForbidden},
+		{"admin on any hotel", admin, "/hotel/:id", "/hotel/" + hotelIDs[1], hotelRooms, http.StatusOK},
+		{"admin creating hotels", admin, "/hotel", "/hotel", hotels, http.StatusOK},
+		{"missing room", admin, "/room/:id", "/room/" + primitive.NewObjectID().Hex(), room, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serveAs(tt.auth, tt.route, tt.path, tt.middleware); got != tt.want {
+				t.Fatalf("got status %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
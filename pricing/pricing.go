@@ -0,0 +1,171 @@
+// Package pricing turns a room and a stay into a nightly price breakdown.
+package pricing
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+const DefaultCurrency = "USD"
+
+var ErrEmptyStay = errors.New("stay must cover at least one night")
+
+type NightlyRate struct {
+	Night   string  `json:"night"`
+	Price   float64 `json:"price"`
+	Weekend bool    `json:"weekend"`
+	Season  string  `json:"season,omitempty"`
+}
+
+type Quote struct {
+	RoomID          string        `json:"room_id"`
+	Currency        string        `json:"currency"`
+	FromDate        time.Time     `json:"from_date"`
+	TillDate        time.Time     `json:"till_date"`
+	Nights          []NightlyRate `json:"nights"`
+	Subtotal        float64       `json:"subtotal"`
+	DiscountPercent float64       `json:"discount_percent"`
+	Discount        float64       `json:"discount"`
+	Total           float64       `json:"total"`
+}
+
+// QuoteStay prices every night of the stay from the room's rate plan: a
+// matching season wins over the regular rates, weekend rates apply on Friday
+// and Saturday nights, and the largest stay discount the stay qualifies for
+// is taken off the subtotal.
+func QuoteStay(room *types.Room, from, till time.Time) (*Quote, error) {
+	nights := types.Nights(from, till)
+	if len(nights) == 0 {
+		return nil, ErrEmptyStay
+	}
+
+	quote := &Quote{
+		RoomID:   room.ID,
+		Currency: Currency(room),
+		FromDate: from,
+		TillDate: till,
+		Nights:   make([]NightlyRate, 0, len(nights)),
+	}
+
+	for _, night := range nights {
+		rate := nightlyRate(room, night)
+		quote.Nights = append(quote.Nights, rate)
+		quote.Subtotal += rate.Price
+	}
+	quote.Subtotal = roundCents(quote.Subtotal)
+
+	quote.DiscountPercent = stayDiscount(room.Rates.StayDiscounts, len(nights))
+	quote.Discount = roundCents(quote.Subtotal * quote.DiscountPercent / 100)
+	quote.Total = roundCents(quote.Subtotal - quote.Discount)
+
+	return quote, nil
+}
+
+// AverageNight returns the total spread evenly over the nights of the stay.
+func (q *Quote) AverageNight() float64 {
+	return roundCents(q.Total / float64(len(q.Nights)))
+}
+
+// BookedNights returns the price of each night with the quote's stay discount
+// taken off, to keep on the booking.
+func (q *Quote) BookedNights() []types.BookedNight {
+	nights := make([]types.BookedNight, 0, len(q.Nights))
+	for _, rate := range q.Nights {
+		nights = append(nights, types.BookedNight{
+			Night: rate.Night,
+			Price: roundCents(rate.Price * (100 - q.DiscountPercent) / 100),
+		})
+	}
+	return nights
+}
+
+// Currency returns the currency the room is priced in.
+func Currency(room *types.Room) string {
+	if room.Rates.Currency != "" {
+		return room.Rates.Currency
+	}
+	return DefaultCurrency
+}
+
+// IsWeekend reports whether night is a Friday or Saturday night.
+func IsWeekend(night time.Time) bool {
+	day := night.Weekday()
+	return day == time.Friday || day == time.Saturday
+}
+
+func nightlyRate(room *types.Room, night time.Time) NightlyRate {
+	rate := NightlyRate{
+		Night:   night.Format(types.DateLayout),
+		Price:   room.Price,
+		Weekend: IsWeekend(night),
+	}
+
+	weekendPrice := room.Rates.WeekendPrice
+	for _, season := range room.Rates.Seasons {
+		if !night.Before(types.StartOfDay(season.From)) && night.Before(types.StartOfDay(season.Till)) {
+			rate.Season = season.Name
+			rate.Price = season.Price
+			weekendPrice = season.WeekendPrice
+			break
+		}
+	}
+
+	if rate.Weekend && weekendPrice > 0 {
+		rate.Price = weekendPrice
+	}
+	return rate
+}
+
+func stayDiscount(discounts []types.StayDiscount, nights int) float64 {
+	var best types.StayDiscount
+	for _, discount := range discounts {
+		if nights >= discount.MinNights && discount.MinNights > best.MinNights {
+			best = discount
+		}
+	}
+	return best.Percent
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// CancellationCharge splits the booking's total into a refund and a penalty
+// for a cancellation made at now under the hotel's policy.
+func CancellationCharge(policy types.CancellationPolicy, booking *types.Booking, now time.Time) types.BookingCancellation {
+	total := booking.TotalPrice
+
+	var penalty float64
+	switch {
+	case policy.NonRefundable:
+		penalty = total
+	case now.Before(types.StartOfDay(booking.FromDate).AddDate(0, 0, -policy.FreeUntilDays)):
+		penalty = 0
+	case policy.Penalty == types.PenaltyPercent:
+		penalty = total * policy.PenaltyPercent / 100
+	case policy.Penalty == types.PenaltyFirstNight:
+		penalty = firstNightPrice(booking)
+	}
+
+	penalty = roundCents(penalty)
+	return types.BookingCancellation{
+		RefundAmount:  roundCents(total - penalty),
+		PenaltyAmount: penalty,
+	}
+}
+
+func firstNightPrice(booking *types.Booking) float64 {
+	if len(booking.Nights) > 0 {
+		return booking.Nights[0].Price
+	}
+
+	// Bookings made before nightly prices were kept have only the total, so
+	// charge their average night
+	if nights := len(types.Nights(booking.FromDate, booking.TillDate)); nights > 0 {
+		return booking.TotalPrice / float64(nights)
+	}
+	return 0
+}
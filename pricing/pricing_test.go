@@ -0,0 +1,145 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mkabdelrahman/hotel-reservation/types"
+)
+
+func TestCancellationChargeFirstNight(t *testing.T) {
+	room := &types.Room{Price: 100, Rates: types.RatePlan{WeekendPrice: 150}}
+	// Friday to Monday: 150 + 150 + 100
+	from := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	till := from.AddDate(0, 0, 3)
+	policy := types.CancellationPolicy{FreeUntilDays: 7, Penalty: types.PenaltyFirstNight}
+	now := from.AddDate(0, 0, -1)
+
+	quote, err := QuoteStay(room, from, till)
+	if err != nil {
+		t.Fatal(err)
+	}
+	booking := &types.Booking{FromDate: from, TillDate: till, TotalPrice: quote.Total, Nights: quote.BookedNights()}
+
+	got := CancellationCharge(policy, booking, now)
+	want := types.BookingCancellation{PenaltyAmount: 150, RefundAmount: 250}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+
+	// Bookings without nightly prices are charged their average night
+	booking.Nights = nil
+	got = CancellationCharge(policy, booking, now)
+	want = types.BookingCancellation{PenaltyAmount: 133.33, RefundAmount: 266.67}
+	if got != want {
+		t.Fatalf("without nightly prices: got %+v, want %+v", got, want)
+	}
+}
+
+func TestQuoteStay(t *testing.T) {
+	// Thursday, so the stay's second and third nights are the weekend
+	thursday := time.Date(2027, 7, 1, 0, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return thursday.AddDate(0, 0, n) }
+
+	summer := types.SeasonalRate{Name: "summer", From: day(1), Till: day(3), Price: 120, WeekendPrice: 180}
+
+	tests := []struct {
+		name       string
+		rates      types.RatePlan
+		nights     int
+		wantPrices []float64
+		wantTotal  float64
+	}{
+		{
+			name:       "base price",
+			nights:     2,
+			wantPrices: []float64{100, 100},
+			wantTotal:  200,
+		},
+		{
+			name:       "weekend rate on Friday and Saturday",
+			rates:      types.RatePlan{WeekendPrice: 150},
+			nights:     4,
+			wantPrices: []float64{100, 150, 150, 100},
+			wantTotal:  500,
+		},
+		{
+			name:       "season covers its nights only, with its own weekend rate",
+			rates:      types.RatePlan{WeekendPrice: 150, Seasons: []types.SeasonalRate{summer}},
+			nights:     4,
+			wantPrices: []float64{100, 180, 180, 100},
+			wantTotal:  560,
+		},
+		{
+			name:       "season without a weekend rate keeps its price on weekends",
+			rates:      types.RatePlan{WeekendPrice: 150, Seasons: []types.SeasonalRate{{Name: "low", From: day(0), Till: day(7), Price: 80}}},
+			nights:     3,
+			wantPrices: []float64{80, 80, 80},
+			wantTotal:  240,
+		},
+		{
+			name:       "season dates are whole days, whatever their time",
+			rates:      types.RatePlan{Seasons: []types.SeasonalRate{{Name: "summer", From: day(1).Add(18 * time.Hour), Till: day(2).Add(18 * time.Hour), Price: 120}}},
+			nights:     3,
+			wantPrices: []float64{100, 120, 100},
+			wantTotal:  320,
+		},
+		{
+			name: "first matching season wins where seasons overlap",
+			rates: types.RatePlan{Seasons: []types.SeasonalRate{
+				{Name: "festival", From: day(1), Till: day(2), Price: 300},
+				{Name: "summer", From: day(0), Till: day(7), Price: 120},
+			}},
+			nights:     3,
+			wantPrices: []float64{120, 300, 120},
+			wantTotal:  540,
+		},
+		{
+			name: "largest stay discount the stay qualifies for",
+			rates: types.RatePlan{StayDiscounts: []types.StayDiscount{
+				{MinNights: 3, Percent: 5},
+				{MinNights: 7, Percent: 15},
+				{MinNights: 14, Percent: 25},
+			}},
+			nights:     7,
+			wantPrices: []float64{100, 100, 100, 100, 100, 100, 100},
+			wantTotal:  595,
+		},
+		{
+			name:       "too short for a stay discount",
+			rates:      types.RatePlan{StayDiscounts: []types.StayDiscount{{MinNights: 3, Percent: 5}}},
+			nights:     2,
+			wantPrices: []float64{100, 100},
+			wantTotal:  200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := &types.Room{Price: 100, Rates: tt.rates}
+			quote, err := QuoteStay(room, thursday, day(tt.nights))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(quote.Nights) != len(tt.wantPrices) {
+				t.Fatalf("got %d nights, want %d", len(quote.Nights), len(tt.wantPrices))
+			}
+			for i, night := range quote.Nights {
+				if night.Price != tt.wantPrices[i] {
+					t.Errorf("night %s: got %v, want %v", night.Night, night.Price, tt.wantPrices[i])
+				}
+			}
+			if quote.Total != tt.wantTotal {
+				t.Errorf("got total %v, want %v", quote.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestQuoteStayEmpty(t *testing.T) {
+	day := time.Date(2027, 7, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := QuoteStay(&types.Room{Price: 100}, day, day); !errors.Is(err, ErrEmptyStay) {
+		t.Fatalf("got %v, want %v", err, ErrEmptyStay)
+	}
+}
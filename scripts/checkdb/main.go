@@ -0,0 +1,291 @@
+// Command checkdb reports, and with -repair fixes, broken links between
+// hotels, rooms, users and bookings:
+//
+//   - users that share an email, which keep the API from starting; they are
+//     only reported, as merging accounts is left to an admin
+//   - rooms whose HotelID is empty or points at a deleted hotel; a room
+//     without HotelID that a hotel still lists in its legacy rooms array is
+//     given that hotel, any other is deleted
+//   - room IDs in the legacy rooms array of hotels that do not match a room of
+//     the hotel; the array is no longer read, so repair removes it
+//   - bookings whose user or room is deleted; repair deletes them
+//   - active bookings that do not hold all their nights in the booking slots
+//     collection, as those stored before it existed; repair holds them, unless
+//     another booking already holds one of the nights
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ardanlabs/conf/v3"
+	"github.com/mkabdelrahman/hotel-reservation/db"
+	"github.com/mkabdelrahman/hotel-reservation/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	dbName      = "hotel-reservation"
+	hotelColl   = "hotels"
+	roomColl    = "rooms"
+	userColl    = "users"
+	bookingColl = "bookings"
+)
+
+type Config struct {
+	MONGODB_URI string `conf:"default:mongodb://localhost:27017,flag:dburi,env:DB_URI"`
+	Repair      bool   `conf:"default:false,flag:repair"`
+}
+
+// legacyHotel reads the rooms array hotels used to store.
+type legacyHotel struct {
+	ID    string   `bson:"_id"`
+	Rooms []string `bson:"rooms"`
+}
+
+type checker struct {
+	repair bool
+	found  int
+
+	hotels       *mongo.Collection
+	roomStore    *db.MongoRoomStore
+	userStore    *db.MongoUserStore
+	bookingStore *db.MongoBookingStore
+}
+
+func main() {
+	var cfg Config
+
+	help, err := conf.Parse("", &cfg)
+	if err != nil {
+		if errors.Is(err, conf.ErrHelpWanted) {
+			fmt.Println(help)
+			return
+		}
+		log.Fatalf("Error parsing configuration: %v\n", err)
+	}
+
+	ctx := context.Background()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MONGODB_URI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Disconnect(ctx)
+
+	c := &checker{
+		repair:       cfg.Repair,
+		hotels:       client.Database(dbName).Collection(hotelColl),
+		roomStore:    db.NewMongoRoomStore(client, dbName, roomColl),
+		userStore:    db.NewMongoUserStore(client, dbName, userColl),
+		bookingStore: db.NewMongoBookingStore(client, dbName, bookingColl),
+	}
+
+	if err := c.run(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	switch {
+	case c.found == 0:
+		fmt.Println("no problems found")
+	case c.repair:
+		fmt.Printf("repaired %d problems\n", c.found)
+	default:
+		fmt.Printf("found %d problems, run with -repair to fix them\n", c.found)
+	}
+}
+
+func (c *checker) run(ctx context.Context) error {
+	if err := c.checkUserEmails(ctx); err != nil {
+		return err
+	}
+
+	cursor, err := c.hotels.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var hotels []legacyHotel
+	if err := cursor.All(ctx, &hotels); err != nil {
+		return err
+	}
+
+	rooms, err := c.roomStore.ListRooms(ctx)
+	if err != nil {
+		return err
+	}
+
+	liveRooms, err := c.checkRooms(ctx, hotels, rooms)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkHotelRoomArrays(ctx, hotels, liveRooms); err != nil {
+		return err
+	}
+
+	return c.checkBookings(ctx, liveRooms)
+}
+
+// checkRooms reports rooms that do not belong to an existing hotel and
+// returns the rooms left after repair, by ID.
+func (c *checker) checkRooms(ctx context.Context, hotels []legacyHotel, rooms []*types.Room) (map[string]*types.Room, error) {
+	hotelIDs := map[string]bool{}
+	listedBy := map[string]string{}
+	for _, hotel := range hotels {
+		hotelIDs[hotel.ID] = true
+		for _, roomID := range hotel.Rooms {
+			listedBy[roomID] = hotel.ID
+		}
+	}
+
+	liveRooms := map[string]*types.Room{}
+	for _, room := range rooms {
+		if hotelIDs[room.HotelID] {
+			liveRooms[room.ID] = room
+			continue
+		}
+		c.found++
+
+		if hotelID, ok := listedBy[room.ID]; ok && room.HotelID == "" {
+			fmt.Printf("room %s has no hotel but is listed by hotel %s\n", room.ID, hotelID)
+			liveRooms[room.ID] = room
+			if c.repair {
+				room.HotelID = hotelID
+				if err := c.roomStore.UpdateRoom(ctx, room); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		fmt.Printf("room %s belongs to missing hotel %q\n", room.ID, room.HotelID)
+		if c.repair {
+			if err := c.roomStore.DeleteRoom(ctx, room.ID); err != nil {
+				return nil, err
+			}
+		} else {
+			liveRooms[room.ID] = room
+		}
+	}
+	return liveRooms, nil
+}
+
+// checkHotelRoomArrays reports room IDs in the legacy rooms arrays that do not
+// match a room of the hotel, and removes the arrays on repair.
+func (c *checker) checkHotelRoomArrays(ctx context.Context, hotels []legacyHotel, liveRooms map[string]*types.Room) error {
+	for _, hotel := range hotels {
+		dangling := 0
+		for _, roomID := range hotel.Rooms {
+			room, ok := liveRooms[roomID]
+			if ok && (room.HotelID == hotel.ID || room.HotelID == "") {
+				continue
+			}
+			fmt.Printf("hotel %s lists room %s, which is not one of its rooms\n", hotel.ID, roomID)
+			dangling++
+		}
+		c.found += dangling
+
+		if c.repair && dangling > 0 {
+			oid, err := primitive.ObjectIDFromHex(hotel.ID)
+			if err != nil {
+				return err
+			}
+			if _, err := c.hotels.UpdateByID(ctx, oid, bson.M{"$unset": bson.M{"rooms": ""}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkBookings reports bookings of deleted users or rooms and deletes them
+// on repair, which also frees the nights they held.
+func (c *checker) checkBookings(ctx context.Context, liveRooms map[string]*types.Room) error {
+	users, err := c.userStore.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+	userIDs := map[string]bool{}
+	for _, user := range users {
+		userIDs[user.ID.Hex()] = true
+	}
+
+	bookings, err := c.bookingStore.GetBookings(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, booking := range bookings {
+		_, roomExists := liveRooms[booking.RoomID]
+		if userIDs[booking.UserID] && roomExists {
+			if err := c.checkBookingSlots(ctx, booking); err != nil {
+				return err
+			}
+			continue
+		}
+		c.found++
+
+		if !userIDs[booking.UserID] {
+			fmt.Printf("booking %s belongs to missing user %s\n", booking.ID, booking.UserID)
+		}
+		if !roomExists {
+			fmt.Printf("booking %s is for missing room %s\n", booking.ID, booking.RoomID)
+		}
+
+		if c.repair {
+			if err := c.bookingStore.DeleteBookingByID(ctx, booking.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkBookingSlots reports an active booking that does not hold all its
+// nights, and holds them on repair. A night another booking holds is a double
+// booking, which is only reported.
+func (c *checker) checkBookingSlots(ctx context.Context, booking *types.Booking) error {
+	missing, err := c.bookingStore.CountMissingSlots(ctx, booking)
+	if err != nil || missing == 0 {
+		return err
+	}
+	c.found++
+	fmt.Printf("booking %s does not hold %d of its nights\n", booking.ID, missing)
+
+	if !c.repair {
+		return nil
+	}
+	err = c.bookingStore.UpdateBooking(ctx, booking)
+	if errors.Is(err, types.ErrRoomUnavailable) {
+		fmt.Printf("booking %s overlaps another booking of room %s, resolve it by hand\n", booking.ID, booking.RoomID)
+		return nil
+	}
+	return err
+}
+
+// checkUserEmails reports users that share an email.
+func (c *checker) checkUserEmails(ctx context.Context) error {
+	users, err := c.userStore.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	byEmail := map[string][]string{}
+	for _, user := range users {
+		byEmail[user.Email] = append(byEmail[user.Email], user.ID.Hex())
+	}
+	for email, userIDs := range byEmail {
+		if len(userIDs) < 2 {
+			continue
+		}
+		c.found++
+		fmt.Printf("users %s share the email %s\n", strings.Join(userIDs, ", "), email)
+	}
+	return nil
+}
@@ -20,6 +20,16 @@ const (
 	roomColl    = "rooms"
 	userColl    = "users"
 	bookingColl = "bookings"
+
+	reservationColl = "reservations"
+	sessionColl     = "sessions"
+	userTokenColl   = "user_tokens"
+	outboxColl      = "outbox"
+
+	loginAttemptColl = "login_attempts"
+	settingsColl     = "settings"
+	apiKeyColl       = "api_keys"
+	oidcLoginColl    = "oidc_logins"
 )
 
 var (
@@ -34,6 +44,19 @@ var (
 
 	bookingStore *db.MongoBookingStore
 
+	reservationStore *db.MongoReservationStore
+
+	sessionStore *db.MongoSessionStore
+
+	userTokenStore *db.MongoUserTokenStore
+
+	outboxStore *db.MongoOutboxStore
+
+	loginAttemptStore *db.MongoLoginAttemptStore
+	settingsStore     *db.MongoSettingsStore
+	apiKeyStore       *db.MongoAPIKeyStore
+	oidcLoginStore    *db.MongoOIDCLoginStore
+
 	manager *business.Manager
 )
 
@@ -58,17 +81,52 @@ func Init(ctx context.Context) {
 		log.Fatal(err)
 	}
 
-	hotelStore = db.NewMongoHotelStore(client, dbName, hotelColl)
+	hotelStore = db.NewMongoHotelStore(client, dbName, hotelColl, roomColl, bookingColl)
 	roomStore = db.NewMongoRoomStore(client, dbName, roomColl)
 	userStore = db.NewMongoUserStore(client, dbName, userColl)
 	bookingStore = db.NewMongoBookingStore(client, dbName, bookingColl)
+	reservationStore = db.NewMongoReservationStore(client, dbName, reservationColl)
+	sessionStore = db.NewMongoSessionStore(client, dbName, sessionColl)
+	userTokenStore = db.NewMongoUserTokenStore(client, dbName, userTokenColl)
+	outboxStore = db.NewMongoOutboxStore(client, dbName, outboxColl)
+	loginAttemptStore = db.NewMongoLoginAttemptStore(client, dbName, loginAttemptColl)
+	settingsStore = db.NewMongoSettingsStore(client, dbName, settingsColl)
+	apiKeyStore = db.NewMongoAPIKeyStore(client, dbName, apiKeyColl)
+	oidcLoginStore = db.NewMongoOIDCLoginStore(client, dbName, oidcLoginColl)
 
 	hotelStore.Drop(ctx)
 	roomStore.Drop(ctx)
 	userStore.Drop(ctx)
 	bookingStore.Drop(ctx)
+	reservationStore.Drop(ctx)
+	sessionStore.Drop(ctx)
+	userTokenStore.Drop(ctx)
+	outboxStore.Drop(ctx)
+	loginAttemptStore.Drop(ctx)
+	settingsStore.Drop(ctx)
+	apiKeyStore.Drop(ctx)
+	oidcLoginStore.Drop(ctx)
+
+	if err := userStore.EnsureIndexes(ctx); err != nil {
+		log.Fatal(err)
+	}
 
-	manager = business.NewManager(userStore, hotelStore, roomStore, bookingStore)
+	manager = business.NewManager(db.Stores{
+		HotelStore:   hotelStore,
+		RoomStore:    roomStore,
+		UserStore:    userStore,
+		BookingStore: bookingStore,
+
+		ReservationStore: reservationStore,
+		SessionStore:     sessionStore,
+		UserTokenStore:   userTokenStore,
+		OutboxStore:      outboxStore,
+
+		LoginAttemptStore: loginAttemptStore,
+		SettingsStore:     settingsStore,
+		APIKeyStore:       apiKeyStore,
+		OIDCLoginStore:    oidcLoginStore,
+	})
 
 }
 func main() {
@@ -84,17 +142,25 @@ func main() {
 
 	// Seed users
 	seedUsers(ctx, users)
-	seedHotel(ctx, "Dolcica", "Madrid", types.Excellent)
-	seedHotel(ctx, "Lapache", "Paris", types.Average)
+	seedHotel(ctx, "Dolcica", "Madrid", types.Excellent, types.CancellationPolicy{
+		FreeUntilDays: 7,
+		Penalty:       types.PenaltyFirstNight,
+	})
+	seedHotel(ctx, "Lapache", "Paris", types.Average, types.CancellationPolicy{
+		FreeUntilDays:  2,
+		Penalty:        types.PenaltyPercent,
+		PenaltyPercent: 50,
+	})
 
 }
 
-func seedHotel(ctx context.Context, name string, location string, rating types.Rating) {
+func seedHotel(ctx context.Context, name string, location string, rating types.Rating, policy types.CancellationPolicy) {
 
 	hotelID, err := manager.AddNewHotel(ctx, types.NewHotelParams{
-		Name:     name,
-		Location: location,
-		Rating:   rating,
+		Name:               name,
+		Location:           location,
+		Rating:             rating,
+		CancellationPolicy: policy,
 	})
 
 	if err != nil {
@@ -107,15 +173,19 @@ func seedHotel(ctx context.Context, name string, location string, rating types.R
 			Floor:       1,
 			Type:        types.DeluxeRoom,
 			Price:       150.0,
-			Occupied:    false,
 			Description: "Spacious room with a city view.",
+			Rates: types.RatePlan{
+				WeekendPrice: 180.0,
+				StayDiscounts: []types.StayDiscount{
+					{MinNights: 7, Percent: 10},
+				},
+			},
 		},
 		{
 			Number:      "202",
 			Floor:       2,
 			Type:        types.StandardRoom,
 			Price:       100.0,
-			Occupied:    false,
 			Description: "Cozy room with modern amenities.",
 		},
 		{
@@ -123,7 +193,6 @@ func seedHotel(ctx context.Context, name string, location string, rating types.R
 			Floor:       3,
 			Type:        types.SuiteRoom,
 			Price:       200.0,
-			Occupied:    false,
 			Description: "Luxurious suite with a balcony and sea view.",
 		},
 		{
@@ -131,7 +200,6 @@ func seedHotel(ctx context.Context, name string, location string, rating types.R
 			Floor:       4,
 			Type:        types.DeluxeRoom,
 			Price:       160.0,
-			Occupied:    false,
 			Description: "Elegant room with premium furnishings.",
 		},
 	}
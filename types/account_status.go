@@ -0,0 +1,85 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAccountSuspended = errors.New("account is suspended")
+	ErrAccountDeleted   = errors.New("account is deleted")
+	// ErrAccountNotVerified refuses accounts whose email is not verified yet
+	ErrAccountNotVerified = errors.New("account email is not verified")
+)
+
+type AccountStatus string
+
+const (
+	AccountActive              AccountStatus = "active"
+	AccountSuspended           AccountStatus = "suspended"
+	AccountPendingVerification AccountStatus = "pending_verification"
+	AccountDeleted             AccountStatus = "deleted"
+)
+
+func (s AccountStatus) Valid() bool {
+	switch s {
+	case AccountActive, AccountSuspended, AccountPendingVerification, AccountDeleted:
+		return true
+	}
+	return false
+}
+
+// CanLogIn returns the reason an account with the status may not log in or
+// use its tokens, or nil.
+func (s AccountStatus) CanLogIn() error {
+	switch s {
+	case AccountSuspended:
+		return ErrAccountSuspended
+	case AccountDeleted:
+		return ErrAccountDeleted
+	case AccountPendingVerification:
+		return ErrAccountNotVerified
+	}
+	return nil
+}
+
+type SetAccountStatusParams struct {
+	Status AccountStatus `json:"status"`
+	Reason string        `json:"reason"`
+	// CancelFutureBookings cancels the bookings the user has not arrived for
+	// yet; it only goes with suspended and deleted.
+	CancelFutureBookings bool `json:"cancelFutureBookings"`
+}
+
+func (params SetAccountStatusParams) Validate() error {
+	if !params.Status.Valid() {
+		return errors.New("status must be one of active, suspended, pending_verification and deleted")
+	}
+
+	if params.Reason == "" {
+		return errors.New("reason is required")
+	}
+
+	if params.CancelFutureBookings && params.Status != AccountSuspended && params.Status != AccountDeleted {
+		return errors.New("cancelFutureBookings only goes with suspended and deleted")
+	}
+	return nil
+}
+
+// AccountStatusChange records the last change of an account's status.
+type AccountStatusChange struct {
+	Reason    string    `bson:"reason" json:"reason"`
+	ChangedBy string    `bson:"changedBy" json:"changedBy"`
+	ChangedAt time.Time `bson:"changedAt" json:"changedAt"`
+}
+
+type ResendVerificationParams struct {
+	Email string `json:"email"`
+}
+
+func (params ResendVerificationParams) Validate() error {
+	if !isEmailValid(params.Email) {
+		return errors.New("email is invalid")
+	}
+	return nil
+}
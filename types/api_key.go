@@ -0,0 +1,79 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidAPIKey is returned for an unknown, revoked or expired API key.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrScopeNotGranted refuses a key scope its user's role does not grant.
+	ErrScopeNotGranted = errors.New("scope is not granted by the user's role")
+)
+
+// An APIKey lets a script call the API as its user without logging in. It
+// may do what both its scopes and its user's role allow. Only the hash of the
+// key is stored; Prefix is the start of the key, to tell keys apart.
+type APIKey struct {
+	ID         string       `json:"id" bson:"_id,omitempty"`
+	Name       string       `json:"name" bson:"name"`
+	UserID     string       `json:"user_id" bson:"user_id"`
+	Scopes     []Permission `json:"scopes" bson:"scopes"`
+	Prefix     string       `json:"prefix" bson:"prefix"`
+	KeyHash    string       `json:"-" bson:"key_hash"`
+	CreatedBy  string       `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time    `json:"created_at" bson:"created_at"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k *APIKey) HasScope(perm Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == perm {
+			return true
+		}
+	}
+	return false
+}
+
+type NewAPIKeyParams struct {
+	Name      string       `json:"name"`
+	UserID    string       `json:"user_id"`
+	Scopes    []Permission `json:"scopes"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+func (params NewAPIKeyParams) Validate() error {
+	if params.Name == "" {
+		return errors.New("name is required")
+	}
+	if params.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if len(params.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range params.Scopes {
+		if !scope.Valid() {
+			return fmt.Errorf("scope %q is not a permission", scope)
+		}
+	}
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+// NewAPIKey is returned when a key is created, the only time the key itself
+// is shown.
+type NewAPIKey struct {
+	APIKey *APIKey `json:"api_key"`
+	Key    string  `json:"key"`
+}
@@ -0,0 +1,72 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the format of calendar dates (nights) in query strings and responses.
+const DateLayout = "2006-01-02"
+
+// MaxQueryNights bounds the date range of availability and quote queries.
+const MaxQueryNights = 90
+
+// DateRangeQuery is a stay from the night of From until the morning of Till.
+type DateRangeQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02" time_utc:"1" binding:"required"`
+	Till time.Time `form:"till" time_format:"2006-01-02" time_utc:"1" binding:"required"`
+}
+
+func (q DateRangeQuery) Validate() error {
+	if !q.Till.After(q.From) {
+		return errors.New("till must be after from")
+	}
+
+	if len(Nights(q.From, q.Till)) > MaxQueryNights {
+		return fmt.Errorf("date range must not exceed %d nights", MaxQueryNights)
+	}
+	return nil
+}
+
+// AvailabilityQuery is a date range, optionally restricted to rooms that sleep
+// at least Guests guests.
+type AvailabilityQuery struct {
+	DateRangeQuery
+	Guests int `form:"guests"`
+}
+
+func (q AvailabilityQuery) Validate() error {
+	if q.Guests < 0 {
+		return errors.New("guests must not be negative")
+	}
+	return q.DateRangeQuery.Validate()
+}
+
+type RoomAvailability struct {
+	RoomID     string   `json:"room_id"`
+	Number     string   `json:"number"`
+	Type       RoomType `json:"type"`
+	Price      float64  `json:"price"`
+	Capacity   int      `json:"capacity"`
+	FreeNights []string `json:"free_nights"`
+	// Available is true when every night of the requested range is free.
+	Available bool `json:"available"`
+}
+
+// Nights returns the start of every night between from and till. A stay from
+// the 3rd till the 7th covers the nights of the 3rd, 4th, 5th and 6th, whatever
+// the check-in and check-out times.
+func Nights(from, till time.Time) []time.Time {
+	var nights []time.Time
+	for night := StartOfDay(from); night.Before(StartOfDay(till)); night = night.AddDate(0, 0, 1) {
+		nights = append(nights, night)
+	}
+	return nights
+}
+
+// StartOfDay returns midnight UTC of t's UTC date. Nights are UTC calendar days.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -12,14 +12,46 @@ type Booking struct {
 	FromDate      time.Time     `json:"from_date" bson:"from_date"`
 	TillDate      time.Time     `json:"till_date" bson:"till_date"`
 	BookingStatus BookingStatus `json:"booking_status" bson:"booking_status"`
+	Adults        int           `json:"adults" bson:"adults"`
+	Children      int           `json:"children" bson:"children"`
+	// ReservationID is set on bookings that are a line of a multi-room reservation
+	ReservationID string    `json:"reservation_id,omitempty" bson:"reservation_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+
+	// The price quoted when the booking was made
+	TotalPrice float64 `json:"total_price" bson:"total_price"`
+	Currency   string  `json:"currency" bson:"currency"`
+	// Nights are the quoted prices of the booked nights, first night first,
+	// with any stay discount taken off
+	Nights []BookedNight `json:"nights,omitempty" bson:"nights,omitempty"`
+
+	// Cancellation is set when the booking is canceled
+	Cancellation *BookingCancellation `json:"cancellation,omitempty" bson:"cancellation,omitempty"`
+
+	// Modifications lists every change of room or dates, oldest first
+	Modifications []BookingModification `json:"modifications,omitempty" bson:"modifications,omitempty"`
+
+	// Transition timestamps, set when the booking enters the matching status
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty"`
+	CheckedInAt  *time.Time `json:"checked_in_at,omitempty" bson:"checked_in_at,omitempty"`
+	CheckedOutAt *time.Time `json:"checked_out_at,omitempty" bson:"checked_out_at,omitempty"`
+	NoShowAt     *time.Time `json:"no_show_at,omitempty" bson:"no_show_at,omitempty"`
+	CanceledAt   *time.Time `json:"canceled_at,omitempty" bson:"canceled_at,omitempty"`
+	ExpiredAt    *time.Time `json:"expired_at,omitempty" bson:"expired_at,omitempty"`
+}
+
+type BookedNight struct {
+	Night string  `json:"night" bson:"night"`
+	Price float64 `json:"price" bson:"price"`
 }
 
 type NewBookingParams struct {
-	UserID        string        `json:"user_id" bson:"user_id"`
-	RoomID        string        `json:"room_id" bson:"room_id"`
-	FromDate      time.Time     `json:"from_date" bson:"from_date"`
-	TillDate      time.Time     `json:"till_date" bson:"till_date"`
-	BookingStatus BookingStatus `json:"booking_status" bson:"booking_status"`
+	UserID   string    `json:"user_id" bson:"user_id"`
+	RoomID   string    `json:"room_id" bson:"room_id"`
+	FromDate time.Time `json:"from_date" bson:"from_date"`
+	TillDate time.Time `json:"till_date" bson:"till_date"`
+	Adults   int       `json:"adults" bson:"adults"`
+	Children int       `json:"children" bson:"children"`
 }
 
 func (params NewBookingParams) Validate() error {
@@ -43,31 +75,135 @@ func (params NewBookingParams) Validate() error {
 		return errors.New("TillDate must be after FromDate")
 	}
 
+	if len(Nights(params.FromDate, params.TillDate)) == 0 {
+		return errors.New("booking must cover at least one night")
+	}
+
+	if params.Adults < 1 {
+		return errors.New("at least one adult is required")
+	}
+
+	if params.Children < 0 {
+		return errors.New("Children must not be negative")
+	}
+
 	now := time.Now()
 	if params.FromDate.Before(now) {
 		return errors.New("FromDate must be in the future")
 	}
 	return nil
 }
-func NewBookingFromParams(params NewBookingParams) *Booking {
-
-	if params.BookingStatus == "" {
-		params.BookingStatus = "Pending"
-	}
 
+// NewBookingFromParams returns a pending booking; it moves on only through the
+// booking lifecycle.
+func NewBookingFromParams(params NewBookingParams) *Booking {
 	return &Booking{
 		UserID:        params.UserID,
 		RoomID:        params.RoomID,
 		FromDate:      params.FromDate,
 		TillDate:      params.TillDate,
-		BookingStatus: params.BookingStatus,
+		BookingStatus: StatusPending,
+		Adults:        params.Adults,
+		Children:      params.Children,
+		CreatedAt:     time.Now(),
 	}
 }
 
+// Guests returns the number of adults and children staying.
+func (params NewBookingParams) Guests() int {
+	return params.Adults + params.Children
+}
+
+// Guests returns the number of adults and children staying.
+func (b *Booking) Guests() int {
+	return b.Adults + b.Children
+}
+
+// SetStatus moves the booking to status and records when it happened.
+func (b *Booking) SetStatus(status BookingStatus, at time.Time) {
+	b.BookingStatus = status
+
+	switch status {
+	case StatusConfirmed:
+		b.ConfirmedAt = &at
+	case StatusCheckedIn:
+		b.CheckedInAt = &at
+	case StatusCheckedOut:
+		b.CheckedOutAt = &at
+	case StatusNoShow:
+		b.NoShowAt = &at
+	case StatusCanceled:
+		b.CanceledAt = &at
+	case StatusExpired:
+		b.ExpiredAt = &at
+	}
+}
+
+// Overlaps reports whether the booking holds any of the nights of [from, till).
+func (b *Booking) Overlaps(from, till time.Time) bool {
+	return StartOfDay(b.FromDate).Before(StartOfDay(till)) && StartOfDay(b.TillDate).After(StartOfDay(from))
+}
+
+// ModifyBookingParams changes the room and/or dates of a booking. Zero fields
+// keep their current value.
+type ModifyBookingParams struct {
+	RoomID   string    `json:"room_id"`
+	FromDate time.Time `json:"from_date"`
+	TillDate time.Time `json:"till_date"`
+}
+
+func (params ModifyBookingParams) Validate() error {
+	if params.RoomID == "" && params.FromDate.IsZero() && params.TillDate.IsZero() {
+		return errors.New("at least one of RoomID, FromDate and TillDate is required")
+	}
+
+	if !params.FromDate.IsZero() && params.FromDate.Before(time.Now()) {
+		return errors.New("FromDate must be in the future")
+	}
+
+	if !params.FromDate.IsZero() && !params.TillDate.IsZero() && !params.TillDate.After(params.FromDate) {
+		return errors.New("TillDate must be after FromDate")
+	}
+	return nil
+}
+
+// BookingModification records a booking's room, dates and price before and
+// after a change.
+type BookingModification struct {
+	ModifiedAt time.Time `json:"modified_at" bson:"modified_at"`
+
+	PrevRoomID     string    `json:"prev_room_id" bson:"prev_room_id"`
+	PrevFromDate   time.Time `json:"prev_from_date" bson:"prev_from_date"`
+	PrevTillDate   time.Time `json:"prev_till_date" bson:"prev_till_date"`
+	PrevTotalPrice float64   `json:"prev_total_price" bson:"prev_total_price"`
+
+	RoomID     string    `json:"room_id" bson:"room_id"`
+	FromDate   time.Time `json:"from_date" bson:"from_date"`
+	TillDate   time.Time `json:"till_date" bson:"till_date"`
+	TotalPrice float64   `json:"total_price" bson:"total_price"`
+}
+
 type BookingStatus string
 
 const (
-	StatusPending   BookingStatus = "Pending"
-	StatusConfirmed BookingStatus = "Confirmed"
-	StatusCanceled  BookingStatus = "Canceled"
+	StatusPending    BookingStatus = "Pending"
+	StatusConfirmed  BookingStatus = "Confirmed"
+	StatusCheckedIn  BookingStatus = "CheckedIn"
+	StatusCheckedOut BookingStatus = "CheckedOut"
+	StatusNoShow     BookingStatus = "NoShow"
+	StatusCanceled   BookingStatus = "Canceled"
+	// StatusExpired is set on Pending bookings that were not confirmed in time.
+	StatusExpired BookingStatus = "Expired"
 )
+
+// ActiveBookingStatuses are the statuses in which a booking holds its room.
+var ActiveBookingStatuses = []BookingStatus{StatusPending, StatusConfirmed, StatusCheckedIn}
+
+func (s BookingStatus) IsActive() bool {
+	for _, active := range ActiveBookingStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
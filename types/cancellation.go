@@ -0,0 +1,45 @@
+package types
+
+import "errors"
+
+type PenaltyType string
+
+const (
+	// PenaltyNone keeps late cancellations free as well.
+	PenaltyNone       PenaltyType = ""
+	PenaltyPercent    PenaltyType = "percent"
+	PenaltyFirstNight PenaltyType = "first_night"
+)
+
+// CancellationPolicy is set per hotel. Cancellations made at least
+// FreeUntilDays days before arrival are free, later ones are charged Penalty.
+// NonRefundable rates are charged in full whenever they are canceled.
+type CancellationPolicy struct {
+	FreeUntilDays  int         `json:"free_until_days" bson:"free_until_days"`
+	Penalty        PenaltyType `json:"penalty" bson:"penalty"`
+	PenaltyPercent float64     `json:"penalty_percent,omitempty" bson:"penalty_percent,omitempty"`
+	NonRefundable  bool        `json:"non_refundable" bson:"non_refundable"`
+}
+
+func (p CancellationPolicy) Validate() error {
+	if p.FreeUntilDays < 0 {
+		return errors.New("free until days must not be negative")
+	}
+
+	switch p.Penalty {
+	case PenaltyNone, PenaltyFirstNight:
+	case PenaltyPercent:
+		if p.PenaltyPercent <= 0 || p.PenaltyPercent > 100 {
+			return errors.New("penalty percent must be between 0 and 100")
+		}
+	default:
+		return errors.New("penalty must be empty, 'percent' or 'first_night'")
+	}
+	return nil
+}
+
+// BookingCancellation records what a guest gets back when a booking is canceled.
+type BookingCancellation struct {
+	RefundAmount  float64 `json:"refund_amount" bson:"refund_amount"`
+	PenaltyAmount float64 `json:"penalty_amount" bson:"penalty_amount"`
+}
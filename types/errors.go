@@ -1,5 +1,32 @@
 package types
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var ErrNotFound = errors.New("not found")
+
+// ErrInvalidStay is returned for dates that do not make a valid stay.
+var ErrInvalidStay = errors.New("invalid stay")
+
+var ErrRoomUnavailable = errors.New("room is already booked for the specified time range")
+
+// BookingConflictError lists the nights, formatted with DateLayout, on which a
+// room is already held. It matches ErrRoomUnavailable with errors.Is.
+type BookingConflictError struct {
+	RoomID string
+	Nights []string
+}
+
+func (e *BookingConflictError) Error() string {
+	return fmt.Sprintf("%s: nights %s", ErrRoomUnavailable, strings.Join(e.Nights, ", "))
+}
+
+func (e *BookingConflictError) Unwrap() error {
+	return ErrRoomUnavailable
+}
+
+// ErrOverCapacity is returned when more guests stay than a room sleeps.
+var ErrOverCapacity = errors.New("too many guests for the room")
@@ -1,17 +1,26 @@
 package types
 
+import "errors"
+
 type Hotel struct {
-	ID       string   `json:"id" bson:"_id,omitempty"`
-	Name     string   `json:"name" bson:"name"`
-	Location string   `json:"location" bson:"location"`
-	Rooms    []string `json:"room_ids" bson:"rooms"`
-	Rating   Rating   `json:"rating" bson:"rating"`
+	ID       string `json:"id" bson:"_id,omitempty"`
+	Name     string `json:"name" bson:"name"`
+	Location string `json:"location" bson:"location"`
+	Rating   Rating `json:"rating" bson:"rating"`
+
+	// Rooms is filled from the rooms' HotelID when the hotel is read through
+	// the business layer; it is not stored.
+	Rooms []string `json:"room_ids" bson:"-"`
+
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy" bson:"cancellation_policy"`
 }
 
 type NewHotelParams struct {
 	Name     string `json:"name" bson:"name"`
 	Location string `json:"location" bson:"location"`
 	Rating   Rating `json:"rating" bson:"rating"`
+
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy" bson:"cancellation_policy"`
 }
 
 func NewHotelFromParams(params NewHotelParams) *Hotel {
@@ -19,6 +28,86 @@ func NewHotelFromParams(params NewHotelParams) *Hotel {
 		Name:     params.Name,
 		Location: params.Location,
 		Rating:   params.Rating,
+
+		CancellationPolicy: params.CancellationPolicy,
 	}
 	return hotel
 }
+
+func (params NewHotelParams) Validate() error {
+	if params.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if params.Location == "" {
+		return errors.New("location is required")
+	}
+
+	if err := validateRating(params.Rating); err != nil {
+		return err
+	}
+
+	return params.CancellationPolicy.Validate()
+}
+
+func validateRating(rating Rating) error {
+	if rating < Poor || rating > Excellent {
+		return errors.New("rating must be between 1 and 5")
+	}
+	return nil
+}
+
+// UpdateHotelParams changes some fields of a hotel. Nil fields keep their
+// current value.
+type UpdateHotelParams struct {
+	Name     *string `json:"name"`
+	Location *string `json:"location"`
+	Rating   *Rating `json:"rating"`
+
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy"`
+}
+
+func (params UpdateHotelParams) Validate() error {
+	if params.Name != nil && *params.Name == "" {
+		return errors.New("name must not be empty")
+	}
+
+	if params.Location != nil && *params.Location == "" {
+		return errors.New("location must not be empty")
+	}
+
+	if params.Rating != nil {
+		if err := validateRating(*params.Rating); err != nil {
+			return err
+		}
+	}
+
+	if params.CancellationPolicy != nil {
+		return params.CancellationPolicy.Validate()
+	}
+	return nil
+}
+
+// Apply returns the params that replace the hotel's fields after the update.
+func (params UpdateHotelParams) Apply(hotel *Hotel) NewHotelParams {
+	replacement := NewHotelParams{
+		Name:     hotel.Name,
+		Location: hotel.Location,
+		Rating:   hotel.Rating,
+
+		CancellationPolicy: hotel.CancellationPolicy,
+	}
+	if params.Name != nil {
+		replacement.Name = *params.Name
+	}
+	if params.Location != nil {
+		replacement.Location = *params.Location
+	}
+	if params.Rating != nil {
+		replacement.Rating = *params.Rating
+	}
+	if params.CancellationPolicy != nil {
+		replacement.CancellationPolicy = *params.CancellationPolicy
+	}
+	return replacement
+}
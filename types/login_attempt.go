@@ -0,0 +1,36 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCredentials is the one error for an unknown email and a wrong
+// password, so a failed login does not tell which accounts exist.
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+var ErrTooManyAttempts = errors.New("too many failed login attempts")
+
+// LoginThrottledError tells when logging in may be tried again. It matches
+// ErrTooManyAttempts with errors.Is.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// LoginAttempts counts the failed logins for an account or a client IP, which
+// Key names, since the last successful one.
+type LoginAttempts struct {
+	Key           string     `json:"key" bson:"_id"`
+	Failures      int        `json:"failures" bson:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" bson:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty" bson:"locked_until,omitempty"`
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMFARequired refuses a session that did not pass a second factor while
+	// the user's role requires one.
+	ErrMFARequired       = errors.New("two-factor authentication is required")
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+)
+
+// UserMFA is the user's TOTP second factor. Until the first code is confirmed
+// it is only pending and not asked for at login.
+type UserMFA struct {
+	// Secret is needed to check codes, so it cannot be stored hashed
+	Secret    string     `bson:"secret" json:"-"`
+	Enabled   bool       `bson:"enabled" json:"enabled"`
+	EnabledAt *time.Time `bson:"enabledAt,omitempty" json:"enabledAt,omitempty"`
+
+	// LastUsedStep is the TOTP time step of the last code accepted; codes of
+	// this step or earlier are refused so none is used twice
+	LastUsedStep int64 `bson:"lastUsedStep" json:"-"`
+
+	// RecoveryCodeHashes are the hashes of the unused recovery codes
+	RecoveryCodeHashes []string `bson:"recoveryCodeHashes,omitempty" json:"-"`
+}
+
+// MFAEnabled reports whether the user must give a second factor at login.
+func (u *User) MFAEnabled() bool {
+	return u.MFA != nil && u.MFA.Enabled
+}
+
+// MFAPolicy lists the roles that must use two-factor authentication. Users
+// of those roles without it are made to set it up at their next login.
+type MFAPolicy struct {
+	RequiredRoles []Role    `bson:"requiredRoles" json:"requiredRoles"`
+	UpdatedBy     string    `bson:"updatedBy,omitempty" json:"updatedBy,omitempty"`
+	UpdatedAt     time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+func (p *MFAPolicy) Requires(role Role) bool {
+	for _, r := range p.RequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type SetMFAPolicyParams struct {
+	RequiredRoles []Role `json:"requiredRoles"`
+}
+
+func (params SetMFAPolicyParams) Validate() error {
+	for _, role := range params.RequiredRoles {
+		if !role.Valid() {
+			return fmt.Errorf("role %q must be one of guest, staff, hotel_manager and admin", role)
+		}
+	}
+	return nil
+}
+
+// MFAChallenge is the answer to a correct password when a second factor is
+// needed. Token is sent back with the code to finish logging in.
+type MFAChallenge struct {
+	MFARequired bool      `json:"mfa_required"`
+	Token       string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	// EnrollmentRequired asks the user to set up TOTP, with the token, first
+	EnrollmentRequired bool `json:"enrollment_required"`
+}
+
+// TOTPEnrollment is a new TOTP secret, shown once to add to an authenticator
+// app. The first code from the app confirms it.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFACodeParams carries a TOTP code or a recovery code.
+type MFACodeParams struct {
+	Code string `json:"code"`
+}
+
+func (params MFACodeParams) Validate() error {
+	if strings.TrimSpace(params.Code) == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
+
+type MFALoginParams struct {
+	Token string `json:"mfa_token"`
+	Code  string `json:"code"`
+}
+
+func (params MFALoginParams) Validate() error {
+	if params.Token == "" {
+		return errors.New("mfa_token is required")
+	}
+	return MFACodeParams{Code: params.Code}.Validate()
+}
+
+type MFAEnrollParams struct {
+	Token string `json:"mfa_token"`
+}
+
+func (params MFAEnrollParams) Validate() error {
+	if params.Token == "" {
+		return errors.New("mfa_token is required")
+	}
+	return nil
+}
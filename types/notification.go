@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationPasswordReset     NotificationKind = "password_reset"
+	NotificationEmailVerification NotificationKind = "email_verification"
+)
+
+// A Notification is a message to a user, kept in the outbox until it is sent.
+type Notification struct {
+	ID      string           `json:"id" bson:"_id,omitempty"`
+	Kind    NotificationKind `json:"kind" bson:"kind"`
+	UserID  string           `json:"user_id" bson:"user_id"`
+	To      string           `json:"to" bson:"to"`
+	Subject string           `json:"subject" bson:"subject"`
+
+	// Body may carry a secret code or link, so it is emptied once the
+	// notification is sent or expires
+	Body      string     `json:"body" bson:"body"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+
+	// ExpiresAt, if set, is when the notification is no longer worth sending;
+	// ExpiredAt is when it was dropped unsent for that reason
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at,omitempty"`
+	ExpiredAt *time.Time `json:"expired_at,omitempty" bson:"expired_at,omitempty"`
+
+	// Attempts counts failed sends; LastError is the error of the last one
+	Attempts  int    `json:"attempts" bson:"attempts"`
+	LastError string `json:"last_error,omitempty" bson:"last_error,omitempty"`
+}
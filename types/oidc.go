@@ -0,0 +1,37 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrInvalidOIDCState refuses a callback that does not end a login
+	// started here, or ends one a second time or too late.
+	ErrInvalidOIDCState = errors.New("invalid or expired login state")
+	// ErrOIDCEmailNotVerified refuses identities whose email the provider has
+	// not verified, as they could claim anyone's account.
+	ErrOIDCEmailNotVerified = errors.New("identity provider has not verified the email")
+)
+
+// ExternalIdentity links a user to their account at an identity provider.
+type ExternalIdentity struct {
+	Provider string    `bson:"provider" json:"provider"`
+	Subject  string    `bson:"subject" json:"subject"`
+	LinkedAt time.Time `bson:"linkedAt" json:"linkedAt"`
+}
+
+// OIDCLogin is a login sent to an identity provider and not back yet. It is
+// found by the hash of its state and keeps the PKCE verifier and the nonce
+// away from the browser.
+type OIDCLogin struct {
+	ID           string     `json:"id" bson:"_id,omitempty"`
+	Provider     string     `json:"provider" bson:"provider"`
+	StateHash    string     `json:"-" bson:"state_hash"`
+	Nonce        string     `json:"-" bson:"nonce"`
+	CodeVerifier string     `json:"-" bson:"code_verifier"`
+	CreatedAt    time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt    time.Time  `json:"expires_at" bson:"expires_at"`
+	UsedAt       *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
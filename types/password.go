@@ -0,0 +1,64 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrWrongPassword = errors.New("current password is wrong")
+
+// HashPassword returns the bcrypt hash stored as User.EncryptedPassword.
+func HashPassword(password string) (string, error) {
+	encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(encryptedPassword), nil
+}
+
+type ChangePasswordParams struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+func (params ChangePasswordParams) Validate() error {
+	if params.CurrentPassword == "" {
+		return errors.New("currentPassword is required")
+	}
+	if params.NewPassword == params.CurrentPassword {
+		return errors.New("newPassword must differ from currentPassword")
+	}
+	return validatePassword(params.NewPassword)
+}
+
+type PasswordResetRequestParams struct {
+	Email string `json:"email"`
+}
+
+func (params PasswordResetRequestParams) Validate() error {
+	if !isEmailValid(params.Email) {
+		return errors.New("email is invalid")
+	}
+	return nil
+}
+
+type PasswordResetParams struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"newPassword"`
+}
+
+func (params PasswordResetParams) Validate() error {
+	if params.Token == "" {
+		return errors.New("token is required")
+	}
+	return validatePassword(params.NewPassword)
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password length should be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
@@ -1,5 +1,94 @@
 package types
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// QueryCriteria searches hotels. Zero fields do not filter. When From and Till
+// are set, only hotels with a matching room that is free for the whole stay
+// are returned.
 type QueryCriteria struct {
-	Rating Rating `form:"rating" binding:"required"`
+	Rating   Rating    `form:"rating"`
+	Location string    `form:"location"`
+	From     time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	Till     time.Time `form:"till" time_format:"2006-01-02" time_utc:"1"`
+	// Guests keeps rooms that sleep at least that many guests
+	Guests   int      `form:"guests"`
+	RoomType RoomType `form:"room_type"`
+	MinPrice float64  `form:"min_price"`
+	MaxPrice float64  `form:"max_price"`
+}
+
+func (c QueryCriteria) Validate() error {
+	if c.From.IsZero() != c.Till.IsZero() {
+		return errors.New("from and till must be given together")
+	}
+
+	if c.HasDates() {
+		if err := (DateRangeQuery{From: c.From, Till: c.Till}).Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Guests < 0 {
+		return errors.New("guests must not be negative")
+	}
+
+	if c.MinPrice < 0 || c.MaxPrice < 0 {
+		return errors.New("prices must not be negative")
+	}
+
+	if c.MaxPrice > 0 && c.MinPrice > c.MaxPrice {
+		return fmt.Errorf("min_price must not exceed max_price")
+	}
+	return nil
+}
+
+// HasDates reports whether the search is restricted to rooms free for a stay.
+func (c QueryCriteria) HasDates() bool {
+	return !c.From.IsZero() && !c.Till.IsZero()
+}
+
+// MatchesRoom reports whether the room has the type and capacity searched
+// for. Price and availability are checked separately.
+func (c QueryCriteria) MatchesRoom(room *Room) bool {
+	if c.RoomType != 0 && room.Type != c.RoomType {
+		return false
+	}
+	return room.Capacity() >= c.Guests
+}
+
+// MatchesPrice reports whether a nightly price is in the range searched for.
+func (c QueryCriteria) MatchesPrice(price float64) bool {
+	if price < c.MinPrice {
+		return false
+	}
+	return c.MaxPrice == 0 || price <= c.MaxPrice
+}
+
+// HotelSearchResult is a hotel with a room matching the search.
+type HotelSearchResult struct {
+	Hotel *Hotel `json:"hotel"`
+	// The lowest nightly price among the matching rooms; for a search with
+	// dates, the average night of the stay as quoted for booking
+	CheapestPrice float64 `json:"cheapest_price"`
+	CheapestRoom  string  `json:"cheapest_room_id"`
+	// CheapestTotal is the quoted price of the stay in the cheapest room, for
+	// a search with dates
+	CheapestTotal float64 `json:"cheapest_total,omitempty"`
+	// AvailableRooms counts the matching rooms
+	AvailableRooms int `json:"available_rooms"`
+}
+
+// AddRoom counts a matching room at its nightly price and stay total,
+// keeping the cheapest.
+func (r *HotelSearchResult) AddRoom(room *Room, price, total float64) {
+	if r.AvailableRooms == 0 || price < r.CheapestPrice {
+		r.CheapestPrice = price
+		r.CheapestRoom = room.ID
+		r.CheapestTotal = total
+	}
+	r.AvailableRooms++
 }
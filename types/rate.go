@@ -0,0 +1,56 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// RatePlan refines a room's flat Price. Every field is optional; a room
+// without a rate plan costs Price per night.
+type RatePlan struct {
+	Currency string `json:"currency,omitempty" bson:"currency,omitempty"`
+	// WeekendPrice applies to Friday and Saturday nights.
+	WeekendPrice  float64        `json:"weekend_price,omitempty" bson:"weekend_price,omitempty"`
+	Seasons       []SeasonalRate `json:"seasons,omitempty" bson:"seasons,omitempty"`
+	StayDiscounts []StayDiscount `json:"stay_discounts,omitempty" bson:"stay_discounts,omitempty"`
+}
+
+// SeasonalRate overrides the nightly price for the nights in [From, Till).
+type SeasonalRate struct {
+	Name         string    `json:"name" bson:"name"`
+	From         time.Time `json:"from" bson:"from"`
+	Till         time.Time `json:"till" bson:"till"`
+	Price        float64   `json:"price" bson:"price"`
+	WeekendPrice float64   `json:"weekend_price,omitempty" bson:"weekend_price,omitempty"`
+}
+
+// StayDiscount takes Percent off stays of at least MinNights nights.
+type StayDiscount struct {
+	MinNights int     `json:"min_nights" bson:"min_nights"`
+	Percent   float64 `json:"percent" bson:"percent"`
+}
+
+func (r RatePlan) Validate() error {
+	if r.WeekendPrice < 0 {
+		return errors.New("weekend price must not be negative")
+	}
+
+	for _, season := range r.Seasons {
+		if !season.Till.After(season.From) {
+			return errors.New("season till must be after from")
+		}
+		if season.Price <= 0 || season.WeekendPrice < 0 {
+			return errors.New("season prices must be positive")
+		}
+	}
+
+	for _, discount := range r.StayDiscounts {
+		if discount.MinNights < 1 {
+			return errors.New("stay discount min nights must be at least 1")
+		}
+		if discount.Percent <= 0 || discount.Percent >= 100 {
+			return errors.New("stay discount percent must be between 0 and 100")
+		}
+	}
+	return nil
+}
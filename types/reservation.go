@@ -0,0 +1,102 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// A Reservation groups the bookings of several rooms that share dates and
+// guest details. Each room is one line, stored as a Booking with ReservationID.
+type Reservation struct {
+	ID         string            `json:"id" bson:"_id,omitempty"`
+	UserID     string            `json:"user_id" bson:"user_id"`
+	FromDate   time.Time         `json:"from_date" bson:"from_date"`
+	TillDate   time.Time         `json:"till_date" bson:"till_date"`
+	Guest      GuestDetails      `json:"guest" bson:"guest"`
+	BookingIDs []string          `json:"booking_ids" bson:"booking_ids"`
+	Status     ReservationStatus `json:"status" bson:"status"`
+	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+}
+
+type GuestDetails struct {
+	FirstName string `json:"first_name" bson:"first_name"`
+	LastName  string `json:"last_name" bson:"last_name"`
+	Email     string `json:"email" bson:"email"`
+	Phone     string `json:"phone" bson:"phone"`
+}
+
+type ReservationStatus string
+
+const (
+	ReservationActive ReservationStatus = "Active"
+	// ReservationCanceled is set once every line is canceled or expired.
+	ReservationCanceled ReservationStatus = "Canceled"
+)
+
+// ReservationLine is one room of a reservation and the guests staying in it.
+type ReservationLine struct {
+	RoomID   string `json:"room_id"`
+	Adults   int    `json:"adults"`
+	Children int    `json:"children"`
+}
+
+type NewReservationParams struct {
+	UserID   string            `json:"user_id"`
+	Lines    []ReservationLine `json:"lines"`
+	FromDate time.Time         `json:"from_date"`
+	TillDate time.Time         `json:"till_date"`
+	Guest    GuestDetails      `json:"guest"`
+}
+
+func (params NewReservationParams) Validate() error {
+	if len(params.Lines) == 0 {
+		return errors.New("Lines must list at least one room")
+	}
+
+	seen := map[string]bool{}
+	for _, line := range params.Lines {
+		if seen[line.RoomID] {
+			return errors.New("Lines must not list a room twice")
+		}
+		seen[line.RoomID] = true
+	}
+
+	if params.Guest.FirstName == "" || params.Guest.LastName == "" {
+		return errors.New("guest first and last name are required")
+	}
+
+	if params.Guest.Email != "" && !isEmailValid(params.Guest.Email) {
+		return errors.New("guest email is invalid")
+	}
+
+	// Every line must be a valid booking on its own
+	for _, line := range params.Lines {
+		if err := params.BookingParams(line).Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BookingParams returns the params of the booking of the given line.
+func (params NewReservationParams) BookingParams(line ReservationLine) NewBookingParams {
+	return NewBookingParams{
+		UserID:   params.UserID,
+		RoomID:   line.RoomID,
+		FromDate: params.FromDate,
+		TillDate: params.TillDate,
+		Adults:   line.Adults,
+		Children: line.Children,
+	}
+}
+
+func NewReservationFromParams(params NewReservationParams) *Reservation {
+	return &Reservation{
+		UserID:    params.UserID,
+		FromDate:  params.FromDate,
+		TillDate:  params.TillDate,
+		Guest:     params.Guest,
+		Status:    ReservationActive,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,110 @@
+package types
+
+import "errors"
+
+// Role sets what a user may do besides managing their own account and
+// bookings. Staff and hotel managers act only on the hotels listed in
+// User.HotelIDs; admins act on every hotel.
+type Role string
+
+const (
+	RoleGuest        Role = "guest"
+	RoleStaff        Role = "staff"
+	RoleHotelManager Role = "hotel_manager"
+	RoleAdmin        Role = "admin"
+)
+
+type Permission string
+
+const (
+	// PermCreateBookings books rooms for oneself. Every role has it, so it
+	// only limits API keys.
+	PermCreateBookings Permission = "bookings:create"
+	// PermViewBookings reads the bookings of other users.
+	PermViewBookings Permission = "bookings:view"
+	// PermManageBookings confirms, checks in and out, modifies and cancels
+	// the bookings of other users.
+	PermManageBookings Permission = "bookings:manage"
+	// PermManageRooms edits a hotel and adds, edits and deletes its rooms.
+	PermManageRooms Permission = "rooms:manage"
+	// PermManageHotels creates and deletes hotels.
+	PermManageHotels Permission = "hotels:manage"
+	// PermManageUsers reads and changes the accounts and roles of other users.
+	PermManageUsers Permission = "users:manage"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleGuest:        {PermCreateBookings},
+	RoleStaff:        {PermCreateBookings, PermViewBookings, PermManageBookings},
+	RoleHotelManager: {PermCreateBookings, PermViewBookings, PermManageBookings, PermManageRooms},
+	RoleAdmin:        {PermCreateBookings, PermViewBookings, PermManageBookings, PermManageRooms, PermManageHotels, PermManageUsers},
+}
+
+// Valid reports whether perm is a permission; admins have every one.
+func (perm Permission) Valid() bool {
+	return RoleAdmin.Has(perm)
+}
+
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// IsScoped reports whether the role acts only on the user's hotels.
+func (r Role) IsScoped() bool {
+	return r == RoleStaff || r == RoleHotelManager
+}
+
+func (r Role) Has(perm Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHotelScoped reports whether scoped roles have perm only on their hotels.
+// Booking for oneself is not about any one hotel.
+func (perm Permission) IsHotelScoped() bool {
+	return perm != PermCreateBookings
+}
+
+// Authorize reports whether a user with the role and hotels may use perm on
+// hotelID. An empty hotelID asks for perm across all hotels, which scoped
+// roles never have.
+func Authorize(role Role, hotelIDs []string, perm Permission, hotelID string) bool {
+	if !role.Has(perm) {
+		return false
+	}
+	if !role.IsScoped() || !perm.IsHotelScoped() {
+		return true
+	}
+
+	for _, id := range hotelIDs {
+		if hotelID != "" && id == hotelID {
+			return true
+		}
+	}
+	return false
+}
+
+type SetUserRoleParams struct {
+	Role     Role     `json:"role"`
+	HotelIDs []string `json:"hotelIDs"`
+}
+
+func (params SetUserRoleParams) Validate() error {
+	if !params.Role.Valid() {
+		return errors.New("role must be one of guest, staff, hotel_manager and admin")
+	}
+
+	if params.Role.IsScoped() && len(params.HotelIDs) == 0 {
+		return errors.New("staff and hotel managers need at least one hotel")
+	}
+
+	if !params.Role.IsScoped() && len(params.HotelIDs) > 0 {
+		return errors.New("only staff and hotel managers are scoped to hotels")
+	}
+	return nil
+}
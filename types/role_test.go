@@ -0,0 +1,65 @@
+package types
+
+import "testing"
+
+func TestAuthorize(t *testing.T) {
+	const (
+		ownHotel   = "own"
+		otherHotel = "other"
+		allHotels  = ""
+	)
+	perms := []Permission{PermCreateBookings, PermViewBookings, PermManageBookings, PermManageRooms, PermManageHotels, PermManageUsers}
+
+	// own, other and all list the permissions each role has on its own hotel,
+	// on another hotel and across all hotels
+	tests := []struct {
+		role  Role
+		own   []Permission
+		other []Permission
+		all   []Permission
+	}{
+		{
+			role:  RoleGuest,
+			own:   []Permission{PermCreateBookings},
+			other: []Permission{PermCreateBookings},
+			all:   []Permission{PermCreateBookings},
+		},
+		{
+			role:  RoleStaff,
+			own:   []Permission{PermCreateBookings, PermViewBookings, PermManageBookings},
+			other: []Permission{PermCreateBookings},
+			all:   []Permission{PermCreateBookings},
+		},
+		{
+			role:  RoleHotelManager,
+			own:   []Permission{PermCreateBookings, PermViewBookings, PermManageBookings, PermManageRooms},
+			other: []Permission{PermCreateBookings},
+			all:   []Permission{PermCreateBookings},
+		},
+		{
+			role:  RoleAdmin,
+			own:   perms,
+			other: perms,
+			all:   perms,
+		},
+		{
+			role: Role("unknown"),
+		},
+	}
+	for _, tt := range tests {
+		for _, hotel := range []struct {
+			id   string
+			want []Permission
+		}{{ownHotel, tt.own}, {otherHotel, tt.other}, {allHotels, tt.all}} {
+			for _, perm := range perms {
+				want := false
+				for _, p := range hotel.want {
+					want = want || p == perm
+				}
+				if got := Authorize(tt.role, []string{ownHotel}, perm, hotel.id); got != want {
+					t.Errorf("%s with %s on hotel %q: got %v, want %v", tt.role, perm, hotel.id, got, want)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -12,7 +13,19 @@ type Room struct {
 	Type        RoomType `json:"type" bson:"type"`
 	Description string   `json:"description" bson:"description"`
 	Price       float64  `json:"price" bson:"price"`
-	Occupied    bool     `json:"occupied" bson:"occupied"`
+	Rates       RatePlan `json:"rates" bson:"rates"`
+	// MaxOccupancy overrides the capacity of the room type when set
+	MaxOccupancy int `json:"max_occupancy,omitempty" bson:"max_occupancy,omitempty"`
+	// Closing is set while the room is being deleted and takes no bookings
+	Closing bool `json:"-" bson:"closing,omitempty"`
+}
+
+// Capacity returns the number of guests the room sleeps.
+func (r *Room) Capacity() int {
+	if r.MaxOccupancy > 0 {
+		return r.MaxOccupancy
+	}
+	return r.Type.Capacity()
 }
 
 type NewRoomParams struct {
@@ -21,7 +34,109 @@ type NewRoomParams struct {
 	Type        RoomType `json:"type" bson:"type"`
 	Description string   `json:"description" bson:"description"`
 	Price       float64  `json:"price" bson:"price"`
-	Occupied    bool     `json:"occupied" bson:"occupied"`
+	Rates       RatePlan `json:"rates" bson:"rates"`
+	// MaxOccupancy overrides the capacity of the room type when set
+	MaxOccupancy int `json:"max_occupancy,omitempty" bson:"max_occupancy,omitempty"`
+}
+
+func (params NewRoomParams) Validate() error {
+	if params.Number == "" {
+		return errors.New("number is required")
+	}
+
+	if err := validateRoomType(params.Type); err != nil {
+		return err
+	}
+
+	if params.Price <= 0 {
+		return errors.New("price must be positive")
+	}
+
+	if params.MaxOccupancy < 0 {
+		return errors.New("max occupancy must not be negative")
+	}
+
+	return params.Rates.Validate()
+}
+
+func validateRoomType(rt RoomType) error {
+	if rt < StandardRoom || rt > SuiteRoom {
+		return errors.New("type must be between 1 and 3")
+	}
+	return nil
+}
+
+// UpdateRoomParams changes some fields of a room. Nil fields keep their
+// current value.
+type UpdateRoomParams struct {
+	Number       *string   `json:"number"`
+	Floor        *int      `json:"floor"`
+	Type         *RoomType `json:"type"`
+	Description  *string   `json:"description"`
+	Price        *float64  `json:"price"`
+	Rates        *RatePlan `json:"rates"`
+	MaxOccupancy *int      `json:"max_occupancy"`
+}
+
+func (params UpdateRoomParams) Validate() error {
+	if params.Number != nil && *params.Number == "" {
+		return errors.New("number must not be empty")
+	}
+
+	if params.Type != nil {
+		if err := validateRoomType(*params.Type); err != nil {
+			return err
+		}
+	}
+
+	if params.Price != nil && *params.Price <= 0 {
+		return errors.New("price must be positive")
+	}
+
+	if params.MaxOccupancy != nil && *params.MaxOccupancy < 0 {
+		return errors.New("max occupancy must not be negative")
+	}
+
+	if params.Rates != nil {
+		return params.Rates.Validate()
+	}
+	return nil
+}
+
+// Apply returns the params that replace the room's fields after the update.
+func (params UpdateRoomParams) Apply(room *Room) NewRoomParams {
+	replacement := NewRoomParams{
+		Number:      room.Number,
+		Floor:       room.Floor,
+		Type:        room.Type,
+		Description: room.Description,
+		Price:       room.Price,
+		Rates:       room.Rates,
+
+		MaxOccupancy: room.MaxOccupancy,
+	}
+	if params.Number != nil {
+		replacement.Number = *params.Number
+	}
+	if params.Floor != nil {
+		replacement.Floor = *params.Floor
+	}
+	if params.Type != nil {
+		replacement.Type = *params.Type
+	}
+	if params.Description != nil {
+		replacement.Description = *params.Description
+	}
+	if params.Price != nil {
+		replacement.Price = *params.Price
+	}
+	if params.Rates != nil {
+		replacement.Rates = *params.Rates
+	}
+	if params.MaxOccupancy != nil {
+		replacement.MaxOccupancy = *params.MaxOccupancy
+	}
+	return replacement
 }
 
 func NewRoomFromParams(params NewRoomParams) *Room {
@@ -31,7 +146,9 @@ func NewRoomFromParams(params NewRoomParams) *Room {
 		Type:        params.Type,
 		Description: params.Description,
 		Price:       params.Price,
-		Occupied:    params.Occupied,
+		Rates:       params.Rates,
+
+		MaxOccupancy: params.MaxOccupancy,
 	}
 	return room
 }
@@ -57,3 +174,17 @@ func (rt RoomType) String() string {
 		return fmt.Sprintf("Unknown RoomType: %d", rt)
 	}
 }
+
+// Capacity returns the number of guests a room of this type sleeps by default.
+func (rt RoomType) Capacity() int {
+	switch rt {
+	case StandardRoom:
+		return 2
+	case DeluxeRoom:
+		return 3
+	case SuiteRoom:
+		return 4
+	default:
+		return 0
+	}
+}
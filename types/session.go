@@ -0,0 +1,48 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrSessionRevoked = errors.New("session revoked or expired")
+
+// A Session is one login of a user. It holds the hash of its refresh token,
+// never the token itself, and the ID (jti) of the one access token it still
+// honours; refreshing rotates both, so older access tokens stop working.
+type Session struct {
+	ID               string     `json:"id" bson:"_id,omitempty"`
+	UserID           string     `json:"user_id" bson:"user_id"`
+	RefreshTokenHash string     `json:"-" bson:"refresh_token_hash"`
+	AccessTokenID    string     `json:"-" bson:"access_token_id"`
+	CreatedAt        time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt        time.Time  `json:"expires_at" bson:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	// MFA records that the login passed a second factor
+	MFA bool `json:"mfa" bson:"mfa"`
+}
+
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// AuthTokens is returned on login and refresh. Token keeps its name from when
+// it was the only token handed out.
+type AuthTokens struct {
+	AccessToken  string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	// RecoveryCodes are set once, when logging in confirms a new TOTP setup
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+type RefreshParams struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (params RefreshParams) Validate() error {
+	if params.RefreshToken == "" {
+		return errors.New("refresh_token is required")
+	}
+	return nil
+}
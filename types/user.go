@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 
@@ -11,6 +12,9 @@ import (
 
 const bcryptCost = 12
 
+// ErrEmailTaken is returned for a new user whose email another user has.
+var ErrEmailTaken = errors.New("email is already registered")
+
 const (
 	minFirstNameLength = 2
 	minLastNameLength  = 2
@@ -24,6 +28,38 @@ type User struct {
 	Email             string             `bson:"email" json:"email"`
 	EncryptedPassword string             `bson:"EncryptedPassword" json:"-"`
 	IsAdmin           bool               `bson:"isAdmin" json:"isAdmin"`
+	Role              Role               `bson:"role,omitempty" json:"role,omitempty"`
+	// HotelIDs scopes staff and hotel managers to these hotels
+	HotelIDs []string `bson:"hotelIDs,omitempty" json:"hotelIDs,omitempty"`
+
+	Status       AccountStatus        `bson:"status,omitempty" json:"status,omitempty"`
+	StatusChange *AccountStatusChange `bson:"statusChange,omitempty" json:"statusChange,omitempty"`
+
+	MFA *UserMFA `bson:"mfa,omitempty" json:"mfa,omitempty"`
+
+	// Identities are the user's accounts at identity providers they log in with
+	Identities []ExternalIdentity `bson:"identities,omitempty" json:"identities,omitempty"`
+}
+
+// EffectiveRole returns the user's role. Users stored before roles existed
+// are admins or guests according to IsAdmin.
+func (u *User) EffectiveRole() Role {
+	if u.Role != "" {
+		return u.Role
+	}
+	if u.IsAdmin {
+		return RoleAdmin
+	}
+	return RoleGuest
+}
+
+// EffectiveStatus returns the account's status. Users stored before statuses
+// existed are active.
+func (u *User) EffectiveStatus() AccountStatus {
+	if u.Status == "" {
+		return AccountActive
+	}
+	return u.Status
 }
 
 type NewUserParams struct {
@@ -34,7 +70,7 @@ type NewUserParams struct {
 }
 
 func NewUserFromParams(params NewUserParams) (*User, error) {
-	encryptedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcryptCost)
+	encryptedPassword, err := HashPassword(params.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +79,7 @@ func NewUserFromParams(params NewUserParams) (*User, error) {
 		FirstName:         params.FirstName,
 		LastName:          params.LastName,
 		Email:             params.Email,
-		EncryptedPassword: string(encryptedPassword),
+		EncryptedPassword: encryptedPassword,
 	}, nil
 }
 
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidUserToken = errors.New("token is invalid, used or expired")
+
+// TokenPurpose is what a UserToken lets its holder do.
+type TokenPurpose string
+
+const (
+	TokenPasswordReset TokenPurpose = "password_reset"
+)
+
+// A UserToken is a single-use secret sent to a user, such as a password reset
+// code. Only its hash is stored.
+type UserToken struct {
+	ID        string       `json:"id" bson:"_id,omitempty"`
+	UserID    string       `json:"user_id" bson:"user_id"`
+	Purpose   TokenPurpose `json:"purpose" bson:"purpose"`
+	TokenHash string       `json:"-" bson:"token_hash"`
+	CreatedAt time.Time    `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time    `json:"expires_at" bson:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty" bson:"used_at,omitempty"`
+}
+
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}